@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func checkCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "check",
+		Usage:     "Check whether a service still matches an updates file, exiting with 7 if it drifted",
+		ArgsUsage: "<cluster> <service>",
+		UsageText: "ecs-deploy check [options] <cluster> <service>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:     "json",
+				Usage:    "Print the changes a deploy would make as JSON",
+				Required: false,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 2 {
+				return usageError(cmd, "Please specify a cluster and service to check")
+			}
+			args := cmd.Args()
+
+			data, err := readConfigPayload(cmd.String("updates"))
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read input file"), exitCodeInput)
+			}
+
+			var updates models.Updates
+			if err := yaml.Unmarshal(data, &updates); err != nil {
+				return err
+			}
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			drift, err := svc.Check(ctx, &services.CheckInput{
+				Cluster:       args.Get(0),
+				Service:       args.Get(1),
+				NewConfig:     updates.TaskConfig,
+				ServiceConfig: updates.Service,
+			})
+			if err != nil {
+				return err
+			}
+
+			// the progress goes to stderr, so stdout only has the changes
+			if cmd.Bool("json") {
+				encoded, err := json.Marshal(drift)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(encoded))
+			}
+			if !drift.Empty() {
+				return cli.Exit(color.RedString("%s has drifted from the updates", args.Get(1)), exitCodeDrifted)
+			}
+			return nil
+		},
+	}
+}
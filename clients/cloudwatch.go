@@ -0,0 +1,73 @@
+package clients
+
+//go:generate mockgen -destination=mocks/cloudwatch.go . AlarmClient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/joomcode/errorx"
+)
+
+// describeAlarmsBatch is the most alarm names CloudWatch takes in one call
+const describeAlarmsBatch = 100
+
+type AlarmClient interface {
+	// AlarmsInAlarm returns the given alarms that are currently firing
+	AlarmsInAlarm(ctx context.Context, names []string) ([]string, error)
+}
+
+type alarmClient struct {
+	client *cloudwatch.Client
+}
+
+func NewAlarmClient(client *cloudwatch.Client) AlarmClient {
+	return &alarmClient{client: client}
+}
+
+func (c *alarmClient) AlarmsInAlarm(ctx context.Context, names []string) ([]string, error) {
+	states := map[string]cloudwatchTypes.StateValue{}
+	for start := 0; start < len(names); start += describeAlarmsBatch {
+		end := min(start+describeAlarmsBatch, len(names))
+		if err := c.describeAlarms(ctx, names[start:end], states); err != nil {
+			return nil, err
+		}
+	}
+
+	var firing []string
+	for _, name := range names {
+		state, found := states[name]
+		if !found {
+			return nil, fmt.Errorf("alarm %s not found", name)
+		}
+		if state == cloudwatchTypes.StateValueAlarm {
+			firing = append(firing, name)
+		}
+	}
+	return firing, nil
+}
+
+func (c *alarmClient) describeAlarms(ctx context.Context, names []string, states map[string]cloudwatchTypes.StateValue) error {
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(c.client, &cloudwatch.DescribeAlarmsInput{
+		AlarmNames: names,
+		AlarmTypes: []cloudwatchTypes.AlarmType{cloudwatchTypes.AlarmTypeMetricAlarm, cloudwatchTypes.AlarmTypeCompositeAlarm},
+		MaxRecords: aws.Int32(describeAlarmsBatch),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return errorx.Decorate(err, "unable to describe alarms %s", strings.Join(names, ", "))
+		}
+		for _, alarm := range page.MetricAlarms {
+			states[aws.ToString(alarm.AlarmName)] = alarm.StateValue
+		}
+		for _, alarm := range page.CompositeAlarms {
+			states[aws.ToString(alarm.AlarmName)] = alarm.StateValue
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package clients_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHTTPClient answers with response, or with the queued answers first
+type fakeHTTPClient struct {
+	requests []*http.Request
+	bodies   []string
+	response string
+	answers  []fakeAnswer
+}
+
+type fakeAnswer struct {
+	status int
+	body   string
+}
+
+func (c *fakeHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		body, _ = io.ReadAll(request.Body)
+	}
+	c.requests = append(c.requests, request)
+	c.bodies = append(c.bodies, string(body))
+	answer := fakeAnswer{status: http.StatusOK, body: c.response}
+	if len(c.answers) > 0 {
+		answer, c.answers = c.answers[0], c.answers[1:]
+	}
+	return &http.Response{
+		StatusCode: answer.status,
+		Status:     http.StatusText(answer.status),
+		Body:       io.NopCloser(strings.NewReader(answer.body)),
+	}, nil
+}
+
+func fakeConfig(httpClient aws.HTTPClient) aws.Config {
+	return aws.Config{
+		Region:      "us-west-2",
+		Credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		HTTPClient:  httpClient,
+	}
+}
+
+const describeAlarmsResponse = `<DescribeAlarmsResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/">
+  <DescribeAlarmsResult>
+    <MetricAlarms>
+      <member><AlarmName>errors</AlarmName><StateValue>OK</StateValue></member>
+      <member><AlarmName>latency</AlarmName><StateValue>ALARM</StateValue></member>
+    </MetricAlarms>
+    <CompositeAlarms>
+      <member><AlarmName>health</AlarmName><StateValue>INSUFFICIENT_DATA</StateValue></member>
+    </CompositeAlarms>
+  </DescribeAlarmsResult>
+</DescribeAlarmsResponse>`
+
+func Test_AlarmsInAlarm(t *testing.T) {
+	httpClient := &fakeHTTPClient{response: describeAlarmsResponse}
+	client := clients.NewAlarmClient(cloudwatch.NewFromConfig(fakeConfig(httpClient)))
+
+	firing, err := client.AlarmsInAlarm(context.Background(), []string{"errors", "latency", "health"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"latency"}, firing)
+
+	assert.Len(t, httpClient.requests, 1)
+	request := httpClient.requests[0]
+	assert.Equal(t, "https://monitoring.us-west-2.amazonaws.com/", request.URL.String())
+	assert.Contains(t, request.Header.Get("Authorization"), "/us-west-2/monitoring/aws4_request")
+	form, err := url.ParseQuery(httpClient.bodies[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "DescribeAlarms", form.Get("Action"))
+	assert.Equal(t, "latency", form.Get("AlarmNames.member.2"))
+}
+
+func Test_AlarmsInAlarm_UnknownAlarm(t *testing.T) {
+	client := clients.NewAlarmClient(cloudwatch.NewFromConfig(fakeConfig(&fakeHTTPClient{response: describeAlarmsResponse})))
+
+	_, err := client.AlarmsInAlarm(context.Background(), []string{"errors", "throughput"})
+	assert.Equal(t, "alarm throughput not found", err.Error())
+}
@@ -0,0 +1,96 @@
+package clients
+
+//go:generate mockgen -destination=mocks/codedeploy.go . CodeDeployClient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	codedeployTypes "github.com/aws/aws-sdk-go-v2/service/codedeploy/types"
+	"github.com/joomcode/errorx"
+)
+
+type CodeDeployClient interface {
+	// CreateDeployment starts a deployment of the given AppSpec and returns its id
+	CreateDeployment(ctx context.Context, application string, deploymentGroup string, appSpec string) (string, error)
+	// WaitForDeployment polls the deployment until it succeeds, fails or we run
+	// out of time, writing a dot to progress on every poll
+	WaitForDeployment(ctx context.Context, deploymentID string, timeout time.Duration, progress io.Writer) error
+	// StopDeployment stops the deployment and rolls it back
+	StopDeployment(ctx context.Context, deploymentID string) error
+}
+
+type codeDeployClient struct {
+	client *codedeploy.Client
+}
+
+func NewCodeDeployClient(client *codedeploy.Client) CodeDeployClient {
+	return &codeDeployClient{client: client}
+}
+
+func (c *codeDeployClient) CreateDeployment(ctx context.Context, application string, deploymentGroup string, appSpec string) (string, error) {
+	output, err := c.client.CreateDeployment(ctx, &codedeploy.CreateDeploymentInput{
+		ApplicationName:     aws.String(application),
+		DeploymentGroupName: aws.String(deploymentGroup),
+		Description:         aws.String("deployed with ecs-ship"),
+		Revision: &codedeployTypes.RevisionLocation{
+			RevisionType:   codedeployTypes.RevisionLocationTypeAppSpecContent,
+			AppSpecContent: &codedeployTypes.AppSpecContent{Content: aws.String(appSpec)},
+		},
+	})
+	if err != nil {
+		return "", errorx.Decorate(err, "unable to create deployment")
+	}
+	return aws.ToString(output.DeploymentId), nil
+}
+
+func (c *codeDeployClient) WaitForDeployment(ctx context.Context, deploymentID string, timeout time.Duration, progress io.Writer) error {
+	ticker := time.NewTicker(sleepTime)
+	defer ticker.Stop()
+
+	timeoutChan := time.After(timeout)
+
+	for {
+		select {
+		case <-ticker.C:
+			output, err := c.client.GetDeployment(ctx, &codedeploy.GetDeploymentInput{DeploymentId: aws.String(deploymentID)})
+			if err != nil {
+				return errorx.Decorate(err, "unable to get deployment")
+			}
+			info := output.DeploymentInfo
+			switch info.Status {
+			case codedeployTypes.DeploymentStatusSucceeded:
+				fmt.Fprintln(progress)
+				return nil
+			case codedeployTypes.DeploymentStatusFailed, codedeployTypes.DeploymentStatusStopped:
+				fmt.Fprintln(progress)
+				if info.ErrorInformation != nil {
+					return fmt.Errorf("deployment %s is %s: %s", deploymentID, info.Status, aws.ToString(info.ErrorInformation.Message))
+				}
+				return fmt.Errorf("deployment %s is %s", deploymentID, info.Status)
+			}
+			fmt.Fprint(progress, ".")
+		case <-ctx.Done():
+			fmt.Fprintln(progress)
+			return errorx.Decorate(ctx.Err(), "stopped waiting for the deployment %s", deploymentID)
+		case <-timeoutChan:
+			fmt.Fprintln(progress)
+			return fmt.Errorf("We ran into a timeout while waiting for the deployment %s to succeed", deploymentID)
+		}
+	}
+}
+
+func (c *codeDeployClient) StopDeployment(ctx context.Context, deploymentID string) error {
+	_, err := c.client.StopDeployment(ctx, &codedeploy.StopDeploymentInput{
+		DeploymentId:        aws.String(deploymentID),
+		AutoRollbackEnabled: aws.Bool(true),
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to stop deployment %s", deploymentID)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package clients_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateDeployment(t *testing.T) {
+	httpClient := &fakeHTTPClient{response: `{"deploymentId": "d-123"}`}
+	client := clients.NewCodeDeployClient(codedeploy.NewFromConfig(fakeConfig(httpClient)))
+
+	deploymentID, err := client.CreateDeployment(context.Background(), "app", "group", `{"version":0.0}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "d-123", deploymentID)
+
+	request := httpClient.requests[0]
+	assert.Equal(t, "https://codedeploy.us-west-2.amazonaws.com/", request.URL.String())
+	assert.Equal(t, "CodeDeploy_20141006.CreateDeployment", request.Header.Get("X-Amz-Target"))
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(httpClient.bodies[0]), &body))
+	assert.Equal(t, "app", body["applicationName"])
+	assert.Equal(t, "group", body["deploymentGroupName"])
+	assert.Equal(t, map[string]interface{}{
+		"revisionType":   "AppSpecContent",
+		"appSpecContent": map[string]interface{}{"content": `{"version":0.0}`},
+	}, body["revision"])
+}
+
+func Test_WaitForDeployment_Failed(t *testing.T) {
+	defer clients.SetSleepTime(time.Millisecond)()
+	httpClient := &fakeHTTPClient{answers: []fakeAnswer{
+		{status: http.StatusOK, body: `{"deploymentInfo": {"status": "InProgress"}}`},
+		{status: http.StatusOK, body: `{"deploymentInfo": {"status": "Failed", "errorInformation": {"code": "HEALTH_CONSTRAINTS", "message": "too many unhealthy tasks"}}}`},
+	}}
+	client := clients.NewCodeDeployClient(codedeploy.NewFromConfig(fakeConfig(httpClient)))
+
+	var progress bytes.Buffer
+	err := client.WaitForDeployment(context.Background(), "d-123", time.Minute, &progress)
+	assert.Equal(t, "deployment d-123 is Failed: too many unhealthy tasks", err.Error())
+	assert.Equal(t, ".\n", progress.String())
+	assert.Equal(t, "CodeDeploy_20141006.GetDeployment", httpClient.requests[0].Header.Get("X-Amz-Target"))
+}
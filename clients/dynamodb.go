@@ -0,0 +1,112 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joomcode/errorx"
+)
+
+type dynamoDBLockStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoDBLockStore keeps the locks in a DynamoDB table with a string
+// partition key called lockKey, taking them with conditional writes
+func NewDynamoDBLockStore(client *dynamodb.Client, table string) LockStore {
+	return &dynamoDBLockStore{client: client, table: table}
+}
+
+// isConditionalCheckFailed tells whether the write was refused by its condition
+func isConditionalCheckFailed(err error) bool {
+	var conditionErr *dynamodbTypes.ConditionalCheckFailedException
+	return errors.As(err, &conditionErr)
+}
+
+// lockItemKey is the primary key of the lock item of key
+func lockItemKey(key string) map[string]dynamodbTypes.AttributeValue {
+	return map[string]dynamodbTypes.AttributeValue{"lockKey": &dynamodbTypes.AttributeValueMemberS{Value: key}}
+}
+
+func (s *dynamoDBLockStore) Acquire(ctx context.Context, key string, owner string) error {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item: map[string]dynamodbTypes.AttributeValue{
+			"lockKey":    &dynamodbTypes.AttributeValueMemberS{Value: key},
+			"owner":      &dynamodbTypes.AttributeValueMemberS{Value: owner},
+			"acquiredAt": &dynamodbTypes.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(lockKey)"),
+	})
+	if isConditionalCheckFailed(err) {
+		lock, err := s.current(ctx, key)
+		if err != nil {
+			return err
+		}
+		return &LockHeldError{Key: key, Lock: *lock}
+	}
+	if err != nil {
+		return errorx.Decorate(err, "unable to write the lock of %s", key)
+	}
+	return nil
+}
+
+func (s *dynamoDBLockStore) Release(ctx context.Context, key string, owner string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       lockItemKey(key),
+		ConditionExpression:       aws.String("#owner = :owner"),
+		ExpressionAttributeNames:  map[string]string{"#owner": "owner"},
+		ExpressionAttributeValues: map[string]dynamodbTypes.AttributeValue{":owner": &dynamodbTypes.AttributeValueMemberS{Value: owner}},
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return errorx.Decorate(err, "unable to delete the lock of %s", key)
+	}
+	return nil
+}
+
+func (s *dynamoDBLockStore) Break(ctx context.Context, key string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key:       lockItemKey(key),
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to delete the lock of %s", key)
+	}
+	return nil
+}
+
+func (s *dynamoDBLockStore) current(ctx context.Context, key string) (*Lock, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            lockItemKey(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to read the lock of %s", key)
+	}
+	if len(output.Item) == 0 {
+		return nil, fmt.Errorf("the lock of %s was released while we read it, try again", key)
+	}
+	lock := &Lock{Owner: stringAttribute(output.Item, "owner")}
+	lock.AcquiredAt, err = time.Parse(time.RFC3339, stringAttribute(output.Item, "acquiredAt"))
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to read the lock of %s", key)
+	}
+	return lock, nil
+}
+
+// stringAttribute is the value of a string attribute of the item, empty if
+// it's missing or not a string
+func stringAttribute(item map[string]dynamodbTypes.AttributeValue, name string) string {
+	if value, ok := item[name].(*dynamodbTypes.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -15,16 +16,26 @@ import (
 	"github.com/joomcode/errorx"
 )
 
-const sleepTime = 5 * time.Second
+var sleepTime = 5 * time.Second
 
 type ECSClient interface {
 	GetService(ctx context.Context, clusterName string, serviceName string) (*ecsTypes.Service, error)
 	DoesServiceLookGood(ctx context.Context, service *ecsTypes.Service) (bool, error)
-	WaitForServiceToLookGood(ctx context.Context, service *ecsTypes.Service, timeout time.Duration) error
+	WaitForServiceToLookGood(ctx context.Context, service *ecsTypes.Service, timeout time.Duration, progress io.Writer) error
 	GetTaskDefinition(ctx context.Context, service *ecsTypes.Service) (*ecs.DescribeTaskDefinitionOutput, error)
+	DescribeTaskDefinition(ctx context.Context, taskDefinition string) (*ecs.DescribeTaskDefinitionOutput, error)
+	ListTaskDefinitionRevisions(ctx context.Context, family string) ([]string, error)
 	CopiedTaskDefinition(output *ecs.DescribeTaskDefinitionOutput) *ecs.RegisterTaskDefinitionInput
 	RegisterTaskDefinition(ctx context.Context, input *ecs.RegisterTaskDefinitionInput) (*ecsTypes.TaskDefinition, error)
 	UpdateTaskDefinition(ctx context.Context, service *ecsTypes.Service, task *ecsTypes.TaskDefinition) (*ecsTypes.Service, error)
+	UpdateService(ctx context.Context, input *ecs.UpdateServiceInput) (*ecsTypes.Service, error)
+	CountStoppedTasks(ctx context.Context, service *ecsTypes.Service, taskDefinition string, since time.Time) (int, error)
+	RunTask(ctx context.Context, input *ecs.RunTaskInput) (*ecsTypes.Task, error)
+	WaitForTaskToStop(ctx context.Context, cluster string, taskArn string, timeout time.Duration, progress io.Writer) (*ecsTypes.Task, error)
+	StopTask(ctx context.Context, cluster string, taskArn string, reason string) error
+	DeregisterTaskDefinition(ctx context.Context, taskDefinition string) error
+	DeleteTaskDefinitions(ctx context.Context, taskDefinitions []string) error
+	ListServiceTaskDefinitions(ctx context.Context, cluster string) ([]string, error)
 }
 
 type ecsClient struct {
@@ -86,7 +97,7 @@ func (c *ecsClient) DoesServiceLookGood(ctx context.Context, service *ecsTypes.S
 	return int32(matchCount) == service.DesiredCount, nil
 }
 
-func (c *ecsClient) WaitForServiceToLookGood(ctx context.Context, service *ecsTypes.Service, timeout time.Duration) error {
+func (c *ecsClient) WaitForServiceToLookGood(ctx context.Context, service *ecsTypes.Service, timeout time.Duration, progress io.Writer) error {
 	refreshService, err := c.GetService(ctx, *service.ClusterArn, *service.ServiceName)
 	if err != nil {
 		return errorx.Decorate(err, "unable to get service")
@@ -118,14 +129,17 @@ func (c *ecsClient) WaitForServiceToLookGood(ctx context.Context, service *ecsTy
 				return errorx.Decorate(err, "unable to get service")
 			}
 			var newErrors []string
-			if newErrors, checkedUntil, err = c.getRecentErrorMessages(refreshService, checkedUntil); err != nil {
+			if newErrors, checkedUntil, err = c.getRecentErrorMessages(refreshService, checkedUntil); err == nil {
 				errorMessages = append(errorMessages, newErrors...)
 			}
-			fmt.Print(".")
+			fmt.Fprint(progress, ".")
 		case <-flushTicker.C:
-			fmt.Println()
+			fmt.Fprintln(progress)
+		case <-ctx.Done():
+			fmt.Fprintln(progress)
+			return errorx.Decorate(ctx.Err(), "stopped waiting for the service")
 		case <-timeoutChan:
-			fmt.Println()
+			fmt.Fprintln(progress)
 			if len(errorMessages) == 0 {
 				return errors.New(strings.Join([]string{
 					"We ran into a timeout while waiting for the service to reach steady state",
@@ -173,8 +187,12 @@ func (c *ecsClient) getRecentErrorMessages(service *ecsTypes.Service, after *tim
 }
 
 func (c *ecsClient) GetTaskDefinition(ctx context.Context, service *ecsTypes.Service) (*ecs.DescribeTaskDefinitionOutput, error) {
+	return c.DescribeTaskDefinition(ctx, *service.TaskDefinition)
+}
+
+func (c *ecsClient) DescribeTaskDefinition(ctx context.Context, taskDefinition string) (*ecs.DescribeTaskDefinitionOutput, error) {
 	output, err := c.client.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
-		TaskDefinition: service.TaskDefinition,
+		TaskDefinition: aws.String(taskDefinition),
 		Include:        []ecsTypes.TaskDefinitionField{ecsTypes.TaskDefinitionFieldTags},
 	})
 	if err != nil {
@@ -183,6 +201,29 @@ func (c *ecsClient) GetTaskDefinition(ctx context.Context, service *ecsTypes.Ser
 	return output, nil
 }
 
+// ListTaskDefinitionRevisions lists the ACTIVE revisions of a family, newest first
+func (c *ecsClient) ListTaskDefinitionRevisions(ctx context.Context, family string) ([]string, error) {
+	paginator := ecs.NewListTaskDefinitionsPaginator(c.client, &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       ecsTypes.TaskDefinitionStatusActive,
+		Sort:         ecsTypes.SortOrderDesc,
+	})
+	var arns []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to list task definitions")
+		}
+		for _, arn := range page.TaskDefinitionArns {
+			// the family prefix also matches other families starting with the same name
+			if strings.HasSuffix(arn[:strings.LastIndex(arn, ":")], "/"+family) {
+				arns = append(arns, arn)
+			}
+		}
+	}
+	return arns, nil
+}
+
 func (c *ecsClient) CopiedTaskDefinition(output *ecs.DescribeTaskDefinitionOutput) *ecs.RegisterTaskDefinitionInput {
 	tags := output.Tags
 	if len(tags) == 0 {
@@ -228,3 +269,188 @@ func (c *ecsClient) UpdateTaskDefinition(ctx context.Context, service *ecsTypes.
 	}
 	return output.Service, nil
 }
+
+func (c *ecsClient) UpdateService(ctx context.Context, input *ecs.UpdateServiceInput) (*ecsTypes.Service, error) {
+	output, err := c.client.UpdateService(ctx, input)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to update service")
+	}
+	return output.Service, nil
+}
+
+// CountStoppedTasks counts the tasks of the service running the given task
+// definition that were started after since and have been stopped already
+func (c *ecsClient) CountStoppedTasks(ctx context.Context, service *ecsTypes.Service, taskDefinition string, since time.Time) (int, error) {
+	paginator := ecs.NewListTasksPaginator(c.client, &ecs.ListTasksInput{
+		Cluster:       service.ClusterArn,
+		ServiceName:   service.ServiceName,
+		DesiredStatus: ecsTypes.DesiredStatusStopped,
+	})
+	count := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, errorx.Decorate(err, "unable to list tasks")
+		}
+		if len(page.TaskArns) == 0 {
+			continue
+		}
+		stoppedTasks, err := c.client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: service.ClusterArn,
+			Tasks:   page.TaskArns,
+		})
+		if err != nil {
+			return 0, errorx.Decorate(err, "unable to describe tasks")
+		}
+		for _, task := range stoppedTasks.Tasks {
+			if aws.ToString(task.TaskDefinitionArn) == taskDefinition && task.CreatedAt != nil && task.CreatedAt.After(since) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (c *ecsClient) RunTask(ctx context.Context, input *ecs.RunTaskInput) (*ecsTypes.Task, error) {
+	output, err := c.client.RunTask(ctx, input)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to run task")
+	}
+	if len(output.Tasks) == 0 {
+		reasons := make([]string, 0, len(output.Failures))
+		for _, failure := range output.Failures {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", aws.ToString(failure.Arn), aws.ToString(failure.Reason)))
+		}
+		return nil, fmt.Errorf("the task did not start: %s", strings.Join(reasons, ", "))
+	}
+	return &output.Tasks[0], nil
+}
+
+// WaitForTaskToStop polls the task until it's STOPPED and returns it, writing
+// a dot to progress on every poll
+func (c *ecsClient) WaitForTaskToStop(ctx context.Context, cluster string, taskArn string, timeout time.Duration, progress io.Writer) (*ecsTypes.Task, error) {
+	ticker := time.NewTicker(sleepTime)
+	defer ticker.Stop()
+
+	timeoutChan := time.After(timeout)
+
+	for {
+		select {
+		case <-ticker.C:
+			output, err := c.client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+				Cluster: aws.String(cluster),
+				Tasks:   []string{taskArn},
+			})
+			if err != nil {
+				return nil, errorx.Decorate(err, "unable to describe tasks")
+			}
+			if len(output.Tasks) == 0 {
+				return nil, fmt.Errorf("task %s not found in cluster %s", taskArn, cluster)
+			}
+			if aws.ToString(output.Tasks[0].LastStatus) == string(ecsTypes.DesiredStatusStopped) {
+				fmt.Fprintln(progress)
+				return &output.Tasks[0], nil
+			}
+			fmt.Fprint(progress, ".")
+		case <-ctx.Done():
+			fmt.Fprintln(progress)
+			return nil, errorx.Decorate(ctx.Err(), "stopped waiting for the task %s", taskArn)
+		case <-timeoutChan:
+			fmt.Fprintln(progress)
+			return nil, fmt.Errorf("We ran into a timeout while waiting for the task %s to stop", taskArn)
+		}
+	}
+}
+
+// StopTask stops a running task, telling ECS why
+func (c *ecsClient) StopTask(ctx context.Context, cluster string, taskArn string, reason string) error {
+	_, err := c.client.StopTask(ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(taskArn),
+		Reason:  aws.String(reason),
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to stop task %s", taskArn)
+	}
+	return nil
+}
+
+func (c *ecsClient) DeregisterTaskDefinition(ctx context.Context, taskDefinition string) error {
+	_, err := c.client.DeregisterTaskDefinition(ctx, &ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: aws.String(taskDefinition),
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to deregister task definition")
+	}
+	return nil
+}
+
+// deleteTaskDefinitionsBatch is the most task definitions ECS deletes in one call
+const deleteTaskDefinitionsBatch = 10
+
+// DeleteTaskDefinitions deletes INACTIVE task definitions
+func (c *ecsClient) DeleteTaskDefinitions(ctx context.Context, taskDefinitions []string) error {
+	for start := 0; start < len(taskDefinitions); start += deleteTaskDefinitionsBatch {
+		end := min(start+deleteTaskDefinitionsBatch, len(taskDefinitions))
+		output, err := c.client.DeleteTaskDefinitions(ctx, &ecs.DeleteTaskDefinitionsInput{
+			TaskDefinitions: taskDefinitions[start:end],
+		})
+		if err != nil {
+			return errorx.Decorate(err, "unable to delete task definitions")
+		}
+		if len(output.Failures) > 0 {
+			reasons := make([]string, 0, len(output.Failures))
+			for _, failure := range output.Failures {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", aws.ToString(failure.Arn), aws.ToString(failure.Reason)))
+			}
+			return fmt.Errorf("unable to delete task definitions: %s", strings.Join(reasons, ", "))
+		}
+	}
+	return nil
+}
+
+// describeServicesBatch is the most services ECS describes in one call
+const describeServicesBatch = 10
+
+// ListServiceTaskDefinitions lists the task definitions the services of the
+// cluster use, including the ones of deployments and task sets in progress
+func (c *ecsClient) ListServiceTaskDefinitions(ctx context.Context, cluster string) ([]string, error) {
+	var serviceArns []string
+	paginator := ecs.NewListServicesPaginator(c.client, &ecs.ListServicesInput{Cluster: aws.String(cluster)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to list services")
+		}
+		serviceArns = append(serviceArns, page.ServiceArns...)
+	}
+
+	seen := map[string]bool{}
+	var taskDefinitions []string
+	use := func(taskDefinition *string) {
+		if arn := aws.ToString(taskDefinition); arn != "" && !seen[arn] {
+			seen[arn] = true
+			taskDefinitions = append(taskDefinitions, arn)
+		}
+	}
+	for start := 0; start < len(serviceArns); start += describeServicesBatch {
+		end := min(start+describeServicesBatch, len(serviceArns))
+		output, err := c.client.DescribeServices(ctx, &ecs.DescribeServicesInput{
+			Cluster:  aws.String(cluster),
+			Services: serviceArns[start:end],
+		})
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to describe services")
+		}
+		for _, service := range output.Services {
+			use(service.TaskDefinition)
+			for _, deployment := range service.Deployments {
+				use(deployment.TaskDefinition)
+			}
+			for _, taskSet := range service.TaskSets {
+				use(taskSet.TaskDefinition)
+			}
+		}
+	}
+	return taskDefinitions, nil
+}
@@ -1,9 +1,13 @@
 package clients_test
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/stretchr/testify/assert"
@@ -30,3 +34,33 @@ func Test_CopyTaskDefinition(t *testing.T) {
 	assert.Equal(t, input.Tags[0].Value, &tagVal)
 	assert.Equal(t, input.Cpu, &cpu)
 }
+
+// describeUnstableServiceResponse is a service still moving to a new
+// deployment, whose tasks can't be placed since the deployment started
+const describeUnstableServiceResponse = `{"services": [{
+	"serviceName": "web",
+	"clusterArn": "arn::cluster",
+	"desiredCount": 1,
+	"deployments": [
+		{"status": "PRIMARY", "createdAt": 1700000000},
+		{"status": "ACTIVE", "createdAt": 1690000000}
+	],
+	"events": [
+		{"createdAt": 1700000100, "message": "(service web) was unable to place a task because no container instance met all of its requirements."},
+		{"createdAt": 1690000100, "message": "(service web) was unable to place a task before the deployment."}
+	]
+}]}`
+
+func Test_WaitForServiceToLookGood_ReportsErrors(t *testing.T) {
+	defer clients.SetSleepTime(10 * time.Millisecond)()
+	httpClient := &fakeHTTPClient{response: describeUnstableServiceResponse}
+	client := clients.NewECSClient(ecs.NewFromConfig(fakeConfig(httpClient)))
+
+	err := client.WaitForServiceToLookGood(context.Background(), &ecsTypes.Service{
+		ClusterArn:  aws.String("arn::cluster"),
+		ServiceName: aws.String("web"),
+	}, 100*time.Millisecond, io.Discard)
+	assert.Contains(t, err.Error(), "We found the following errors while trying to get your service up:")
+	assert.Contains(t, err.Error(), "was unable to place a task because no container instance met all of its requirements.")
+	assert.NotContains(t, err.Error(), "before the deployment")
+}
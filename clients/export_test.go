@@ -0,0 +1,11 @@
+package clients
+
+import "time"
+
+// SetSleepTime makes the wait loops poll faster in tests, the returned func
+// puts the default back
+func SetSleepTime(d time.Duration) func() {
+	previous := sleepTime
+	sleepTime = d
+	return func() { sleepTime = previous }
+}
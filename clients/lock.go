@@ -0,0 +1,220 @@
+package clients
+
+//go:generate mockgen -destination=mocks/lock.go . LockStore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/joomcode/errorx"
+)
+
+// lockTag is the service tag holding the lock of the tag store
+const lockTag = "ecs-ship:lock"
+
+type LockStore interface {
+	// Acquire takes the lock on key for owner, failing with a *LockHeldError if somebody else has it
+	Acquire(ctx context.Context, key string, owner string) error
+	// Release gives back the lock on key if owner still has it
+	Release(ctx context.Context, key string, owner string) error
+	// Break drops the lock on key, whoever has it
+	Break(ctx context.Context, key string) error
+}
+
+// Lock is who holds a lock and since when
+type Lock struct {
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+}
+
+// LockHeldError is returned when somebody else holds the lock we want
+type LockHeldError struct {
+	Key  string
+	Lock Lock
+}
+
+func (err *LockHeldError) Error() string {
+	if err.Lock.AcquiredAt.IsZero() {
+		return fmt.Sprintf("%s is locked by %s", err.Key, err.Lock.Owner)
+	}
+	return fmt.Sprintf(
+		"%s is locked by %s since %s (%s ago)",
+		err.Key, err.Lock.Owner, err.Lock.AcquiredAt.Format(time.RFC3339), time.Since(err.Lock.AcquiredAt).Round(time.Second),
+	)
+}
+
+type tagLockStore struct {
+	client *ecs.Client
+}
+
+// NewTagLockStore keeps the locks in a tag of the locked resource, which
+// has to be an ECS resource ARN. Tags can't be written conditionally, so two
+// deploys starting at the very same time can both get the lock.
+func NewTagLockStore(client *ecs.Client) LockStore {
+	return &tagLockStore{client: client}
+}
+
+func (s *tagLockStore) Acquire(ctx context.Context, key string, owner string) error {
+	lock, err := s.current(ctx, key)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		return &LockHeldError{Key: key, Lock: *lock}
+	}
+
+	value := fmt.Sprintf("%s %s", time.Now().UTC().Format(time.RFC3339), owner)
+	_, err = s.client.TagResource(ctx, &ecs.TagResourceInput{
+		ResourceArn: aws.String(key),
+		Tags:        []ecsTypes.Tag{{Key: aws.String(lockTag), Value: aws.String(value)}},
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to tag %s", key)
+	}
+
+	// whoever tagged last wins
+	lock, err = s.current(ctx, key)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("the lock of %s was dropped while we were taking it", key)
+	}
+	if lock.Owner != owner {
+		return &LockHeldError{Key: key, Lock: *lock}
+	}
+	return nil
+}
+
+func (s *tagLockStore) Release(ctx context.Context, key string, owner string) error {
+	lock, err := s.current(ctx, key)
+	if err != nil || lock == nil || lock.Owner != owner {
+		return err
+	}
+	return s.Break(ctx, key)
+}
+
+func (s *tagLockStore) Break(ctx context.Context, key string) error {
+	_, err := s.client.UntagResource(ctx, &ecs.UntagResourceInput{
+		ResourceArn: aws.String(key),
+		TagKeys:     []string{lockTag},
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to untag %s", key)
+	}
+	return nil
+}
+
+// current reads the lock out of the tags of key, nil if there's none
+func (s *tagLockStore) current(ctx context.Context, key string) (*Lock, error) {
+	output, err := s.client.ListTagsForResource(ctx, &ecs.ListTagsForResourceInput{ResourceArn: aws.String(key)})
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to list the tags of %s", key)
+	}
+	for _, tag := range output.Tags {
+		if aws.ToString(tag.Key) != lockTag {
+			continue
+		}
+		acquiredAt, owner, _ := strings.Cut(aws.ToString(tag.Value), " ")
+		lock := &Lock{Owner: owner}
+		lock.AcquiredAt, err = time.Parse(time.RFC3339, acquiredAt)
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to read the lock of %s", key)
+		}
+		return lock, nil
+	}
+	return nil, nil
+}
+
+// fileLockAttempts is how many times we try to create a lock file that
+// disappears while we read it
+const fileLockAttempts = 3
+
+type fileLockStore struct {
+	dir string
+}
+
+// NewFileLockStore keeps the locks in files of a local directory, so only
+// deploys running on the same machine see each other
+func NewFileLockStore(dir string) LockStore {
+	return &fileLockStore{dir: dir}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (s *fileLockStore) path(key string) string {
+	return filepath.Join(s.dir, unsafeFileChars.ReplaceAllString(key, "_")+".lock")
+}
+
+func (s *fileLockStore) Acquire(ctx context.Context, key string, owner string) error {
+	data, err := json.Marshal(&Lock{Owner: owner, AcquiredAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return errorx.Decorate(err, "unable to create the lock directory")
+	}
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(s.path(key), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			lock, err := s.current(key)
+			// the holder released it meanwhile, try again
+			if errors.Is(err, os.ErrNotExist) && attempt < fileLockAttempts {
+				continue
+			}
+			if err != nil {
+				// the holder is still writing it, or left it broken, either way it's taken
+				return &LockHeldError{Key: key, Lock: Lock{Owner: "an unknown deploy"}}
+			}
+			return &LockHeldError{Key: key, Lock: *lock}
+		}
+		if err != nil {
+			return errorx.Decorate(err, "unable to create the lock file")
+		}
+		defer file.Close()
+		if _, err := file.Write(data); err != nil {
+			return errorx.Decorate(err, "unable to write the lock file")
+		}
+		return nil
+	}
+}
+
+func (s *fileLockStore) Release(ctx context.Context, key string, owner string) error {
+	lock, err := s.current(key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || lock.Owner != owner {
+		return err
+	}
+	return s.Break(ctx, key)
+}
+
+func (s *fileLockStore) Break(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errorx.Decorate(err, "unable to remove the lock file")
+	}
+	return nil
+}
+
+func (s *fileLockStore) current(key string) (*Lock, error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, errorx.Decorate(err, "unable to read the lock file")
+	}
+	return &lock, nil
+}
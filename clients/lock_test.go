@@ -0,0 +1,75 @@
+package clients_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileLockStore(t *testing.T) {
+	ctx := context.Background()
+	store := clients.NewFileLockStore(t.TempDir())
+	key := "arn:aws:ecs:us-east-1:123:service/cluster/service"
+
+	assert.Nil(t, store.Acquire(ctx, key, "alice"))
+
+	err := store.Acquire(ctx, key, "bob")
+	var lockErr *clients.LockHeldError
+	assert.ErrorAs(t, err, &lockErr)
+	assert.Equal(t, key, lockErr.Key)
+	assert.Equal(t, "alice", lockErr.Lock.Owner)
+	assert.WithinDuration(t, time.Now(), lockErr.Lock.AcquiredAt, time.Minute)
+
+	// only the owner gives the lock back
+	assert.Nil(t, store.Release(ctx, key, "bob"))
+	assert.ErrorAs(t, store.Acquire(ctx, key, "bob"), &lockErr)
+	assert.Nil(t, store.Release(ctx, key, "alice"))
+	assert.Nil(t, store.Acquire(ctx, key, "bob"))
+
+	assert.Nil(t, store.Break(ctx, key))
+	assert.Nil(t, store.Acquire(ctx, key, "carol"))
+	assert.Nil(t, store.Release(ctx, "unknown", "carol"))
+}
+
+func Test_FileLockStore_Unreadable(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := clients.NewFileLockStore(dir)
+
+	// another deploy is still writing its lock
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "service.lock"), []byte(`{"owner": "al`), 0o644))
+
+	err := store.Acquire(ctx, "service", "bob")
+	var lockErr *clients.LockHeldError
+	assert.ErrorAs(t, err, &lockErr)
+	assert.Equal(t, "service is locked by an unknown deploy", err.Error())
+}
+
+func Test_DynamoDBLockStore_Held(t *testing.T) {
+	httpClient := &fakeHTTPClient{answers: []fakeAnswer{
+		{status: http.StatusBadRequest, body: `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException"}`},
+		{status: http.StatusOK, body: `{"Item":{"lockKey":{"S":"service"},"owner":{"S":"alice"},"acquiredAt":{"S":"2024-05-01T10:00:00Z"}}}`},
+	}}
+	store := clients.NewDynamoDBLockStore(dynamodb.NewFromConfig(fakeConfig(httpClient)), "locks")
+
+	err := store.Acquire(context.Background(), "service", "bob")
+	var lockErr *clients.LockHeldError
+	assert.ErrorAs(t, err, &lockErr)
+	assert.Equal(t, clients.Lock{Owner: "alice", AcquiredAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}, lockErr.Lock)
+
+	assert.Equal(t, "DynamoDB_20120810.PutItem", httpClient.requests[0].Header.Get("X-Amz-Target"))
+	assert.Equal(t, "application/x-amz-json-1.0", httpClient.requests[0].Header.Get("Content-Type"))
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(httpClient.bodies[0]), &body))
+	assert.Equal(t, "locks", body["TableName"])
+	assert.Equal(t, "attribute_not_exists(lockKey)", body["ConditionExpression"])
+	assert.Equal(t, "DynamoDB_20120810.GetItem", httpClient.requests[1].Header.Get("X-Amz-Target"))
+}
@@ -11,6 +11,7 @@ package mock_clients
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -57,6 +58,64 @@ func (mr *MockECSClientMockRecorder) CopiedTaskDefinition(output any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopiedTaskDefinition", reflect.TypeOf((*MockECSClient)(nil).CopiedTaskDefinition), output)
 }
 
+// CountStoppedTasks mocks base method.
+func (m *MockECSClient) CountStoppedTasks(ctx context.Context, service *types.Service, taskDefinition string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountStoppedTasks", ctx, service, taskDefinition, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountStoppedTasks indicates an expected call of CountStoppedTasks.
+func (mr *MockECSClientMockRecorder) CountStoppedTasks(ctx, service, taskDefinition, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountStoppedTasks", reflect.TypeOf((*MockECSClient)(nil).CountStoppedTasks), ctx, service, taskDefinition, since)
+}
+
+// DeleteTaskDefinitions mocks base method.
+func (m *MockECSClient) DeleteTaskDefinitions(ctx context.Context, taskDefinitions []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaskDefinitions", ctx, taskDefinitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaskDefinitions indicates an expected call of DeleteTaskDefinitions.
+func (mr *MockECSClientMockRecorder) DeleteTaskDefinitions(ctx, taskDefinitions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaskDefinitions", reflect.TypeOf((*MockECSClient)(nil).DeleteTaskDefinitions), ctx, taskDefinitions)
+}
+
+// DeregisterTaskDefinition mocks base method.
+func (m *MockECSClient) DeregisterTaskDefinition(ctx context.Context, taskDefinition string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeregisterTaskDefinition", ctx, taskDefinition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeregisterTaskDefinition indicates an expected call of DeregisterTaskDefinition.
+func (mr *MockECSClientMockRecorder) DeregisterTaskDefinition(ctx, taskDefinition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterTaskDefinition", reflect.TypeOf((*MockECSClient)(nil).DeregisterTaskDefinition), ctx, taskDefinition)
+}
+
+// DescribeTaskDefinition mocks base method.
+func (m *MockECSClient) DescribeTaskDefinition(ctx context.Context, taskDefinition string) (*ecs.DescribeTaskDefinitionOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeTaskDefinition", ctx, taskDefinition)
+	ret0, _ := ret[0].(*ecs.DescribeTaskDefinitionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeTaskDefinition indicates an expected call of DescribeTaskDefinition.
+func (mr *MockECSClientMockRecorder) DescribeTaskDefinition(ctx, taskDefinition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeTaskDefinition", reflect.TypeOf((*MockECSClient)(nil).DescribeTaskDefinition), ctx, taskDefinition)
+}
+
 // DoesServiceLookGood mocks base method.
 func (m *MockECSClient) DoesServiceLookGood(ctx context.Context, service *types.Service) (bool, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +161,36 @@ func (mr *MockECSClientMockRecorder) GetTaskDefinition(ctx, service any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskDefinition", reflect.TypeOf((*MockECSClient)(nil).GetTaskDefinition), ctx, service)
 }
 
+// ListServiceTaskDefinitions mocks base method.
+func (m *MockECSClient) ListServiceTaskDefinitions(ctx context.Context, cluster string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceTaskDefinitions", ctx, cluster)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceTaskDefinitions indicates an expected call of ListServiceTaskDefinitions.
+func (mr *MockECSClientMockRecorder) ListServiceTaskDefinitions(ctx, cluster any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceTaskDefinitions", reflect.TypeOf((*MockECSClient)(nil).ListServiceTaskDefinitions), ctx, cluster)
+}
+
+// ListTaskDefinitionRevisions mocks base method.
+func (m *MockECSClient) ListTaskDefinitionRevisions(ctx context.Context, family string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaskDefinitionRevisions", ctx, family)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaskDefinitionRevisions indicates an expected call of ListTaskDefinitionRevisions.
+func (mr *MockECSClientMockRecorder) ListTaskDefinitionRevisions(ctx, family any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaskDefinitionRevisions", reflect.TypeOf((*MockECSClient)(nil).ListTaskDefinitionRevisions), ctx, family)
+}
+
 // RegisterTaskDefinition mocks base method.
 func (m *MockECSClient) RegisterTaskDefinition(ctx context.Context, input *ecs.RegisterTaskDefinitionInput) (*types.TaskDefinition, error) {
 	m.ctrl.T.Helper()
@@ -117,6 +206,50 @@ func (mr *MockECSClientMockRecorder) RegisterTaskDefinition(ctx, input any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterTaskDefinition", reflect.TypeOf((*MockECSClient)(nil).RegisterTaskDefinition), ctx, input)
 }
 
+// RunTask mocks base method.
+func (m *MockECSClient) RunTask(ctx context.Context, input *ecs.RunTaskInput) (*types.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunTask", ctx, input)
+	ret0, _ := ret[0].(*types.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunTask indicates an expected call of RunTask.
+func (mr *MockECSClientMockRecorder) RunTask(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTask", reflect.TypeOf((*MockECSClient)(nil).RunTask), ctx, input)
+}
+
+// StopTask mocks base method.
+func (m *MockECSClient) StopTask(ctx context.Context, cluster, taskArn, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopTask", ctx, cluster, taskArn, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopTask indicates an expected call of StopTask.
+func (mr *MockECSClientMockRecorder) StopTask(ctx, cluster, taskArn, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTask", reflect.TypeOf((*MockECSClient)(nil).StopTask), ctx, cluster, taskArn, reason)
+}
+
+// UpdateService mocks base method.
+func (m *MockECSClient) UpdateService(ctx context.Context, input *ecs.UpdateServiceInput) (*types.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateService", ctx, input)
+	ret0, _ := ret[0].(*types.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateService indicates an expected call of UpdateService.
+func (mr *MockECSClientMockRecorder) UpdateService(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockECSClient)(nil).UpdateService), ctx, input)
+}
+
 // UpdateTaskDefinition mocks base method.
 func (m *MockECSClient) UpdateTaskDefinition(ctx context.Context, service *types.Service, task *types.TaskDefinition) (*types.Service, error) {
 	m.ctrl.T.Helper()
@@ -133,15 +266,30 @@ func (mr *MockECSClientMockRecorder) UpdateTaskDefinition(ctx, service, task any
 }
 
 // WaitForServiceToLookGood mocks base method.
-func (m *MockECSClient) WaitForServiceToLookGood(ctx context.Context, service *types.Service, timeout time.Duration) error {
+func (m *MockECSClient) WaitForServiceToLookGood(ctx context.Context, service *types.Service, timeout time.Duration, progress io.Writer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitForServiceToLookGood", ctx, service, timeout)
+	ret := m.ctrl.Call(m, "WaitForServiceToLookGood", ctx, service, timeout, progress)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WaitForServiceToLookGood indicates an expected call of WaitForServiceToLookGood.
-func (mr *MockECSClientMockRecorder) WaitForServiceToLookGood(ctx, service, timeout any) *gomock.Call {
+func (mr *MockECSClientMockRecorder) WaitForServiceToLookGood(ctx, service, timeout, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForServiceToLookGood", reflect.TypeOf((*MockECSClient)(nil).WaitForServiceToLookGood), ctx, service, timeout, progress)
+}
+
+// WaitForTaskToStop mocks base method.
+func (m *MockECSClient) WaitForTaskToStop(ctx context.Context, cluster, taskArn string, timeout time.Duration, progress io.Writer) (*types.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForTaskToStop", ctx, cluster, taskArn, timeout, progress)
+	ret0, _ := ret[0].(*types.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForTaskToStop indicates an expected call of WaitForTaskToStop.
+func (mr *MockECSClientMockRecorder) WaitForTaskToStop(ctx, cluster, taskArn, timeout, progress any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForServiceToLookGood", reflect.TypeOf((*MockECSClient)(nil).WaitForServiceToLookGood), ctx, service, timeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForTaskToStop", reflect.TypeOf((*MockECSClient)(nil).WaitForTaskToStop), ctx, cluster, taskArn, timeout, progress)
 }
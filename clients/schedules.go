@@ -0,0 +1,234 @@
+package clients
+
+//go:generate mockgen -destination=mocks/schedules.go . ScheduledTaskClient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	// ScheduledTaskRule is an ECS target of an EventBridge rule
+	ScheduledTaskRule = "rule"
+	// ScheduledTaskSchedule is an EventBridge Scheduler schedule running an ECS task
+	ScheduledTaskSchedule = "schedule"
+)
+
+// ScheduledTask is something in EventBridge that runs a task definition on a
+// schedule or when an event happens
+type ScheduledTask struct {
+	// Kind is either ScheduledTaskRule or ScheduledTaskSchedule
+	Kind string
+	// Name is <bus>/<rule>/<target id> for rule targets and <group>/<name> for schedules
+	Name string
+	// TaskDefinition is the task definition it runs
+	TaskDefinition string
+
+	bus      string
+	rule     string
+	target   *eventbridgeTypes.Target
+	schedule *scheduler.GetScheduleOutput
+}
+
+type ScheduledTaskClient interface {
+	// ListScheduledTasks finds the rule targets and schedules that run the task definition on the cluster
+	ListScheduledTasks(ctx context.Context, clusterArn string, taskDefinition string) ([]*ScheduledTask, error)
+	// UpdateScheduledTask points the scheduled task to another task definition
+	UpdateScheduledTask(ctx context.Context, task *ScheduledTask, taskDefinition string) error
+}
+
+type scheduledTaskClient struct {
+	events    *eventbridge.Client
+	scheduler *scheduler.Client
+}
+
+func NewScheduledTaskClient(events *eventbridge.Client, scheduler *scheduler.Client) ScheduledTaskClient {
+	return &scheduledTaskClient{events: events, scheduler: scheduler}
+}
+
+func (c *scheduledTaskClient) ListScheduledTasks(ctx context.Context, clusterArn string, taskDefinition string) ([]*ScheduledTask, error) {
+	ruleTargets, err := c.listRuleTargets(ctx, clusterArn, taskDefinition)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := c.listSchedules(ctx, clusterArn, taskDefinition)
+	if err != nil {
+		return nil, err
+	}
+	return append(ruleTargets, schedules...), nil
+}
+
+func (c *scheduledTaskClient) UpdateScheduledTask(ctx context.Context, task *ScheduledTask, taskDefinition string) error {
+	switch task.Kind {
+	case ScheduledTaskRule:
+		return c.updateRuleTarget(ctx, task, taskDefinition)
+	case ScheduledTaskSchedule:
+		return c.updateSchedule(ctx, task, taskDefinition)
+	}
+	return fmt.Errorf("unknown kind of scheduled task %q", task.Kind)
+}
+
+func (c *scheduledTaskClient) listRuleTargets(ctx context.Context, clusterArn string, taskDefinition string) ([]*ScheduledTask, error) {
+	var buses []string
+	for nextToken := (*string)(nil); ; {
+		output, err := c.events.ListEventBuses(ctx, &eventbridge.ListEventBusesInput{NextToken: nextToken})
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to list event buses")
+		}
+		for _, bus := range output.EventBuses {
+			buses = append(buses, aws.ToString(bus.Name))
+		}
+		if nextToken = output.NextToken; nextToken == nil {
+			break
+		}
+	}
+
+	var tasks []*ScheduledTask
+	for _, bus := range buses {
+		var rules []string
+		for nextToken := (*string)(nil); ; {
+			output, err := c.events.ListRuleNamesByTarget(ctx, &eventbridge.ListRuleNamesByTargetInput{
+				TargetArn:    aws.String(clusterArn),
+				EventBusName: aws.String(bus),
+				NextToken:    nextToken,
+			})
+			if err != nil {
+				return nil, errorx.Decorate(err, "unable to list the rules of %s", bus)
+			}
+			rules = append(rules, output.RuleNames...)
+			if nextToken = output.NextToken; nextToken == nil {
+				break
+			}
+		}
+
+		for _, rule := range rules {
+			for nextToken := (*string)(nil); ; {
+				output, err := c.events.ListTargetsByRule(ctx, &eventbridge.ListTargetsByRuleInput{
+					Rule:         aws.String(rule),
+					EventBusName: aws.String(bus),
+					NextToken:    nextToken,
+				})
+				if err != nil {
+					return nil, errorx.Decorate(err, "unable to list the targets of %s", rule)
+				}
+				for _, target := range output.Targets {
+					if aws.ToString(target.Arn) != clusterArn || target.EcsParameters == nil ||
+						aws.ToString(target.EcsParameters.TaskDefinitionArn) != taskDefinition {
+						continue
+					}
+					tasks = append(tasks, &ScheduledTask{
+						Kind:           ScheduledTaskRule,
+						Name:           strings.Join([]string{bus, rule, aws.ToString(target.Id)}, "/"),
+						TaskDefinition: taskDefinition,
+						bus:            bus,
+						rule:           rule,
+						target:         &target,
+					})
+				}
+				if nextToken = output.NextToken; nextToken == nil {
+					break
+				}
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (c *scheduledTaskClient) updateRuleTarget(ctx context.Context, task *ScheduledTask, taskDefinition string) error {
+	target := *task.target
+	parameters := *target.EcsParameters
+	parameters.TaskDefinitionArn = aws.String(taskDefinition)
+	target.EcsParameters = &parameters
+
+	output, err := c.events.PutTargets(ctx, &eventbridge.PutTargetsInput{
+		Rule:         aws.String(task.rule),
+		EventBusName: aws.String(task.bus),
+		Targets:      []eventbridgeTypes.Target{target},
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to update the target %s", task.Name)
+	}
+	if output.FailedEntryCount > 0 {
+		var reasons []string
+		for _, failure := range output.FailedEntries {
+			reasons = append(reasons, fmt.Sprintf("%s (%s)", aws.ToString(failure.ErrorMessage), aws.ToString(failure.ErrorCode)))
+		}
+		return fmt.Errorf("unable to update the target %s: %s", task.Name, strings.Join(reasons, ", "))
+	}
+	task.target = &target
+	task.TaskDefinition = taskDefinition
+	return nil
+}
+
+func (c *scheduledTaskClient) listSchedules(ctx context.Context, clusterArn string, taskDefinition string) ([]*ScheduledTask, error) {
+	var tasks []*ScheduledTask
+	paginator := scheduler.NewListSchedulesPaginator(c.scheduler, &scheduler.ListSchedulesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, errorx.Decorate(err, "unable to list schedules")
+		}
+
+		for _, summary := range page.Schedules {
+			// the summaries only have the ARN of the target, the task definition needs the whole schedule
+			if summary.Target == nil || aws.ToString(summary.Target.Arn) != clusterArn {
+				continue
+			}
+			schedule, err := c.scheduler.GetSchedule(ctx, &scheduler.GetScheduleInput{
+				Name:      summary.Name,
+				GroupName: summary.GroupName,
+			})
+			if err != nil {
+				return nil, errorx.Decorate(err, "unable to get schedule %s", aws.ToString(summary.Name))
+			}
+			if schedule.Target == nil || aws.ToString(schedule.Target.Arn) != clusterArn || schedule.Target.EcsParameters == nil ||
+				aws.ToString(schedule.Target.EcsParameters.TaskDefinitionArn) != taskDefinition {
+				continue
+			}
+			tasks = append(tasks, &ScheduledTask{
+				Kind:           ScheduledTaskSchedule,
+				Name:           aws.ToString(summary.GroupName) + "/" + aws.ToString(summary.Name),
+				TaskDefinition: taskDefinition,
+				schedule:       schedule,
+			})
+		}
+	}
+	return tasks, nil
+}
+
+func (c *scheduledTaskClient) updateSchedule(ctx context.Context, task *ScheduledTask, taskDefinition string) error {
+	schedule := task.schedule
+	target := *schedule.Target
+	parameters := *target.EcsParameters
+	parameters.TaskDefinitionArn = aws.String(taskDefinition)
+	target.EcsParameters = &parameters
+
+	// UpdateSchedule replaces the whole schedule, everything we don't send is reset
+	_, err := c.scheduler.UpdateSchedule(ctx, &scheduler.UpdateScheduleInput{
+		Name:                       schedule.Name,
+		GroupName:                  schedule.GroupName,
+		ScheduleExpression:         schedule.ScheduleExpression,
+		ScheduleExpressionTimezone: schedule.ScheduleExpressionTimezone,
+		FlexibleTimeWindow:         schedule.FlexibleTimeWindow,
+		Target:                     &target,
+		ActionAfterCompletion:      schedule.ActionAfterCompletion,
+		Description:                schedule.Description,
+		StartDate:                  schedule.StartDate,
+		EndDate:                    schedule.EndDate,
+		KmsKeyArn:                  schedule.KmsKeyArn,
+		State:                      schedule.State,
+	})
+	if err != nil {
+		return errorx.Decorate(err, "unable to update schedule %s", task.Name)
+	}
+	schedule.Target = &target
+	task.TaskDefinition = taskDefinition
+	return nil
+}
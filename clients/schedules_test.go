@@ -0,0 +1,98 @@
+package clients_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ScheduledTasks(t *testing.T) {
+	httpClient := &fakeHTTPClient{answers: []fakeAnswer{
+		{http.StatusOK, `{"EventBuses": [{"Name": "default"}]}`},
+		{http.StatusOK, `{"RuleNames": ["nightly"]}`},
+		{http.StatusOK, `{"Targets": [
+			{"Id": "job", "Arn": "arn:cluster", "RoleArn": "arn:role", "EcsParameters": {"TaskDefinitionArn": "arn:web:1", "TaskCount": 1}},
+			{"Id": "other", "Arn": "arn:cluster", "EcsParameters": {"TaskDefinitionArn": "arn:worker:3"}}
+		]}`},
+		{http.StatusOK, `{"Schedules": [
+			{"Name": "hourly", "GroupName": "jobs", "Target": {"Arn": "arn:cluster"}},
+			{"Name": "cleanup", "GroupName": "default", "Target": {"Arn": "arn:lambda"}}
+		]}`},
+		{http.StatusOK, `{
+			"Arn": "arn:schedule", "Name": "hourly", "GroupName": "jobs", "CreationDate": 1700000000,
+			"ScheduleExpression": "rate(1 hour)", "FlexibleTimeWindow": {"Mode": "OFF"},
+			"Target": {"Arn": "arn:cluster", "RoleArn": "arn:role", "EcsParameters": {"TaskDefinitionArn": "arn:web:1"}}
+		}`},
+	}}
+	client := clients.NewScheduledTaskClient(eventbridge.NewFromConfig(fakeConfig(httpClient)), scheduler.NewFromConfig(fakeConfig(httpClient)))
+	ctx := context.Background()
+
+	tasks, err := client.ListScheduledTasks(ctx, "arn:cluster", "arn:web:1")
+	assert.Nil(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, clients.ScheduledTaskRule, tasks[0].Kind)
+	assert.Equal(t, "default/nightly/job", tasks[0].Name)
+	assert.Equal(t, clients.ScheduledTaskSchedule, tasks[1].Kind)
+	assert.Equal(t, "jobs/hourly", tasks[1].Name)
+	assert.Equal(t, "AWSEvents.ListTargetsByRule", httpClient.requests[2].Header.Get("X-Amz-Target"))
+	assert.Equal(t, "https://scheduler.us-west-2.amazonaws.com/schedules/hourly?groupName=jobs", httpClient.requests[4].URL.String())
+
+	httpClient.response = `{"FailedEntryCount": 0}`
+	assert.Nil(t, client.UpdateScheduledTask(ctx, tasks[0], "arn:web:2"))
+	assert.Equal(t, "arn:web:2", tasks[0].TaskDefinition)
+	var putTargets map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(httpClient.bodies[5]), &putTargets))
+	assert.Equal(t, "nightly", putTargets["Rule"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"Id":            "job",
+		"Arn":           "arn:cluster",
+		"RoleArn":       "arn:role",
+		"EcsParameters": map[string]interface{}{"TaskDefinitionArn": "arn:web:2", "TaskCount": float64(1)},
+	}}, putTargets["Targets"])
+
+	httpClient.response = `{"ScheduleArn": "arn:schedule"}`
+	assert.Nil(t, client.UpdateScheduledTask(ctx, tasks[1], "arn:web:2"))
+	assert.Equal(t, http.MethodPut, httpClient.requests[6].Method)
+	assert.Equal(t, "/schedules/hourly", httpClient.requests[6].URL.Path)
+	var updateSchedule map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(httpClient.bodies[6]), &updateSchedule))
+	// the SDK makes the update idempotent with a token of its own
+	assert.NotEmpty(t, updateSchedule["ClientToken"])
+	delete(updateSchedule, "ClientToken")
+	assert.Equal(t, map[string]interface{}{
+		"GroupName":          "jobs",
+		"ScheduleExpression": "rate(1 hour)",
+		"FlexibleTimeWindow": map[string]interface{}{"Mode": "OFF"},
+		"Target": map[string]interface{}{
+			"Arn":           "arn:cluster",
+			"RoleArn":       "arn:role",
+			"EcsParameters": map[string]interface{}{"TaskDefinitionArn": "arn:web:2"},
+		},
+	}, updateSchedule)
+}
+
+func Test_ScheduledTasks_FailedTarget(t *testing.T) {
+	httpClient := &fakeHTTPClient{answers: []fakeAnswer{
+		{http.StatusOK, `{"EventBuses": [{"Name": "default"}]}`},
+		{http.StatusOK, `{"RuleNames": ["nightly"]}`},
+		{http.StatusOK, `{"Targets": [{"Id": "job", "Arn": "arn:cluster", "EcsParameters": {"TaskDefinitionArn": "arn:web:1"}}]}`},
+		{http.StatusOK, `{"Schedules": []}`},
+		{http.StatusOK, `{"FailedEntryCount": 1, "FailedEntries": [{"TargetId": "job", "ErrorCode": "ConcurrentModificationException", "ErrorMessage": "try again"}]}`},
+	}}
+	client := clients.NewScheduledTaskClient(eventbridge.NewFromConfig(fakeConfig(httpClient)), scheduler.NewFromConfig(fakeConfig(httpClient)))
+	ctx := context.Background()
+
+	tasks, err := client.ListScheduledTasks(ctx, "arn:cluster", "arn:web:1")
+	assert.Nil(t, err)
+	assert.Len(t, tasks, 1)
+
+	err = client.UpdateScheduledTask(ctx, tasks[0], "arn:web:2")
+	assert.EqualError(t, err, "unable to update the target default/nightly/job: try again (ConcurrentModificationException)")
+	assert.Equal(t, "arn:web:1", tasks[0].TaskDefinition)
+}
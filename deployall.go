@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func deployAllCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "deploy-all",
+		Usage:     "Deploy many services described in a manifest",
+		ArgsUsage: "<manifest>",
+		UsageText: "ecs-deploy deploy-all [options] <manifest>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "parallelism",
+				Aliases:  []string{"p"},
+				Usage:    "Run at most `N` deploys at the same time (overrides the manifest)",
+				Value:    4,
+				Required: false,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 1 {
+				return usageError(cmd, "Please specify a manifest to deploy")
+			}
+			for _, flag := range []string{"canary", "codedeploy-application", "codedeploy-deployment-group"} {
+				if cmd.IsSet(flag) {
+					return usageError(cmd, fmt.Sprintf("Please set --%s for each deployment in the manifest", flag))
+				}
+			}
+			if cmd.Bool("break-lock") && cmd.String("lock") == "" {
+				return usageError(cmd, "Please specify the lock store to break the lock in with --lock")
+			}
+			manifestName := cmd.Args().Get(0)
+
+			data, err := readConfigPayload(manifestName)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read manifest file"), exitCodeInput)
+			}
+			manifest, err := models.ParseManifest(data)
+			if err != nil {
+				return cli.Exit(color.RedString("Invalid manifest: %s", err), exitCodeInput)
+			}
+
+			parallelism := manifest.Parallelism
+			if parallelism == 0 || cmd.IsSet("parallelism") {
+				parallelism = int(cmd.Int("parallelism"))
+			}
+
+			// services sharing a region and credentials share a client
+			deployers := make(map[models.Target]services.DeployerService)
+			deployerFor := func(target models.Target) (services.DeployerService, error) {
+				if deployer, ok := deployers[target]; ok {
+					return deployer, nil
+				}
+				deployer, err := newDeployer(ctx, cmd, target)
+				if err != nil {
+					return nil, err
+				}
+				deployers[target] = deployer
+				return deployer, nil
+			}
+
+			defaultTarget := flagsTarget(cmd)
+			defaultDeployer, err := deployerFor(defaultTarget)
+			if err != nil {
+				return err
+			}
+			deployments := make([]*services.BatchDeployment, 0, len(manifest.Deployments))
+			for _, entry := range manifest.Deployments {
+				updates, err := entryUpdates(manifestName, entry)
+				if err != nil {
+					return cli.Exit(color.RedString("Unable to read updates of %s: %s", entry.Name, err), exitCodeInput)
+				}
+				deployer, err := deployerFor(entry.Target.Or(defaultTarget))
+				if err != nil {
+					return errorx.Decorate(err, "unable to reach the services of %s", entry.Name)
+				}
+				deployments = append(deployments, &services.BatchDeployment{
+					Name:      entry.Name,
+					DependsOn: entry.DependsOn,
+					Deployer:  deployer,
+					Input: &services.DeployInput{
+						Cluster:                   entry.Cluster,
+						Service:                   entry.Service,
+						NewConfig:                 updates.TaskConfig,
+						ServiceConfig:             updates.Service,
+						PreDeployTask:             updates.PreDeployTask,
+						Hooks:                     updates.Hooks,
+						DryRun:                    cmd.Bool("dry"),
+						Timeout:                   cmd.Duration("timeout"),
+						NoWait:                    cmd.Bool("no-wait"),
+						ForceNewDeployment:        cmd.Bool("force-new-deployment"),
+						RollbackOnFailure:         cmd.Bool("rollback-on-failure"),
+						RollbackOnInterrupt:       cmd.Bool("rollback-on-interrupt"),
+						LockOwner:                 cmd.String("lock-owner"),
+						BreakLock:                 cmd.Bool("break-lock"),
+						UpdateScheduledTasks:      cmd.Bool("update-scheduled-tasks"),
+						Prune:                     flagsPrune(cmd),
+						Canary:                    entryCanary(cmd, entry),
+						CodeDeployApplication:     entry.CodeDeployApplication,
+						CodeDeployDeploymentGroup: entry.CodeDeployDeploymentGroup,
+					},
+				})
+			}
+
+			svc := services.NewBatchDeployerService(defaultDeployer)
+			return svc.DeployAll(ctx, &services.BatchDeployInput{
+				Deployments: deployments,
+				Parallelism: parallelism,
+			})
+		},
+	}
+}
+
+// entryUpdates gets the updates of a manifest entry, updates files are
+// relative to the manifest
+func entryUpdates(manifestName string, entry *models.ManifestEntry) (*models.Updates, error) {
+	if entry.UpdatesFile == "" {
+		if entry.Updates == nil {
+			return &models.Updates{}, nil
+		}
+		return entry.Updates, nil
+	}
+	path := entry.UpdatesFile
+	if !filepath.IsAbs(path) && manifestName != "-" {
+		path = filepath.Join(filepath.Dir(manifestName), path)
+	}
+	data, err := readConfigPayload(path)
+	if err != nil {
+		return nil, err
+	}
+	var updates models.Updates
+	if err := yaml.Unmarshal(data, &updates); err != nil {
+		return nil, fmt.Errorf("invalid updates file %s: %w", path, err)
+	}
+	return &updates, nil
+}
+
+// entryCanary is the canary of a manifest entry, nil if it has none. The bake
+// time defaults to the --canary-bake-time flag.
+func entryCanary(cmd *cli.Command, entry *models.ManifestEntry) *services.CanaryInput {
+	if entry.Canary == nil {
+		return nil
+	}
+	bakeTime := entry.Canary.BakeTime
+	if bakeTime == 0 {
+		bakeTime = cmd.Duration("canary-bake-time")
+	}
+	return &services.CanaryInput{
+		Service:         entry.Canary.Service,
+		BakeTime:        bakeTime,
+		MaxStoppedTasks: entry.Canary.MaxStoppedTasks,
+		Alarms:          entry.Canary.Alarms,
+	}
+}
@@ -1,22 +1,48 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/adroll/ecs-ship/clients"
 	"github.com/adroll/ecs-ship/models"
 	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v3"
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	exitCodeUsage          = 2
+	exitCodeInput          = 3
+	exitCodeRolledBack     = 4
+	exitCodeRollbackFailed = 5
+	exitCodeLocked         = 6
+	exitCodeDrifted        = 7
+	exitCodeCanaryFailed   = 8
+)
+
 func main() {
 	initLogger()
 
@@ -61,53 +87,215 @@ func main() {
 				Usage:    "Don't deploy just show what would change in the remote service",
 				Required: false,
 			},
+			&cli.BoolFlag{
+				Name:     "force-new-deployment",
+				Aliases:  []string{"f"},
+				Usage:    "Start a new deployment even if nothing changed",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:        "confirm",
+				Usage:       "Ask before changing anything once the changes are shown",
+				DefaultText: "when stdin is a terminal and the updates come from a file",
+				Required:    false,
+			},
+			&cli.BoolFlag{
+				Name:     "yes",
+				Aliases:  []string{"y"},
+				Usage:    "Don't ask for confirmation, for automation",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "region",
+				Usage:    "Use the services in this AWS `REGION`",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "profile",
+				Usage:    "Use this AWS `PROFILE` from your shared configuration",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "role-arn",
+				Usage:    "Assume the role with this `ARN` to reach the services",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "task-definition",
+				Usage:    "Deploy this already registered `REVISION` (family:revision or ARN) instead of registering a new one",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "rollback-on-failure",
+				Aliases:  []string{"r"},
+				Usage:    "Point the service back to its previous task definition if the deploy fails",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "rollback-on-interrupt",
+				Usage:    "Point the service back to its previous task definition if we get interrupted while waiting for the deploy",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "update-scheduled-tasks",
+				Usage:    "Point the EventBridge rules and schedules running the old task definition to the new one",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "prune",
+				Usage:    "Deregister old revisions of the task definition family after a successful deploy",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "keep",
+				Usage:    "Never prune the newest `N` ACTIVE revisions",
+				Value:    10,
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:        "older-than",
+				Usage:       "Only prune revisions registered longer than `DURATION` ago",
+				DefaultText: "any age",
+				Required:    false,
+			},
+			&cli.BoolFlag{
+				Name:     "delete-pruned",
+				Usage:    "Delete the revisions after deregistering them",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "lock",
+				Usage:    "Lock the service while deploying, keeping the lock in `STORE`: tags, dynamodb:<table> or file:<dir>",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:        "lock-owner",
+				Usage:       "Tell who's deploying with this `NAME` in the lock",
+				Value:       defaultLockOwner(),
+				DefaultText: "user@host:pid",
+				Required:    false,
+			},
+			&cli.BoolFlag{
+				Name:     "break-lock",
+				Usage:    "Drop the lock of the service before deploying, when the deploy holding it is gone",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:        "codedeploy-application",
+				Usage:       "Deploy services using the CODE_DEPLOY controller with this CodeDeploy `APPLICATION`",
+				DefaultText: "AppECS-<cluster>-<service>",
+				Required:    false,
+			},
+			&cli.StringFlag{
+				Name:        "codedeploy-deployment-group",
+				Usage:       "Deploy services using the CODE_DEPLOY controller with this CodeDeploy `GROUP`",
+				DefaultText: "DgpECS-<cluster>-<service>",
+				Required:    false,
+			},
+			&cli.StringFlag{
+				Name:     "canary",
+				Usage:    "Deploy to the canary `SERVICE` first and only go on if it stays healthy",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "canary-bake-time",
+				Usage:    "Let the canary run the new task definition for this `DURATION` before checking it",
+				Value:    time.Minute * 5,
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "canary-max-stopped-tasks",
+				Usage:    "Fail the canary if more than `N` of its new tasks stop",
+				Value:    0,
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "canary-alarm",
+				Usage:    "Fail the canary if the CloudWatch alarm `NAME` goes off, can be repeated",
+				Required: false,
+			},
+		},
+		Commands: []*cli.Command{
+			restartCommand(),
+			rollbackCommand(),
+			deployAllCommand(),
+			pruneCommand(),
+			waitCommand(),
+			registerCommand(),
+			promoteCommand(),
+			checkCommand(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			color.NoColor = color.NoColor || cmd.Bool("no-color")
 
 			if cmd.NArg() != 2 {
-				ec := cli.Exit(color.RedString("Please specify a cluster and service to update"), 2)
-				if err := cli.ShowAppHelp(cmd); err != nil {
-					log.Println(color.RedString("failed to show help: %s"), err.Error())
-				}
-				return ec
+				return usageError(cmd, "Please specify a cluster and service to update")
+			}
+			if cmd.Bool("break-lock") && cmd.String("lock") == "" {
+				return usageError(cmd, "Please specify the lock store to break the lock in with --lock")
 			}
 			args := cmd.Args()
 			cluster := args.Get(0)
 			service := args.Get(1)
 
-			data, err := readConfigPayload(cmd.String("updates"))
-			if err != nil {
-				ec := cli.Exit(color.RedString("Unable to read input file"), 3)
-				return ec
-			}
+			// the updates are optional when deploying an existing revision
+			var updates models.Updates
+			readUpdates := !cmd.IsSet("task-definition") || cmd.IsSet("updates")
+			if readUpdates {
+				data, err := readConfigPayload(cmd.String("updates"))
+				if err != nil {
+					ec := cli.Exit(color.RedString("Unable to read input file"), exitCodeInput)
+					return ec
+				}
 
-			var cfg models.TaskConfig
-			if err := yaml.Unmarshal(data, &cfg); err != nil {
-				return err
+				if err := yaml.Unmarshal(data, &updates); err != nil {
+					return err
+				}
+			}
+			if cmd.IsSet("task-definition") && !updates.TaskConfig.Empty() {
+				return usageError(cmd, "Please specify either --task-definition or task definition updates, not both")
 			}
 
-			ecsConfig, err := config.LoadDefaultConfig(ctx)
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
 			if err != nil {
 				return err
 			}
 
-			ecsClient := ecs.NewFromConfig(ecsConfig)
-
-			client := clients.NewECSClient(ecsClient)
-			svc := services.NewDeployerService(client)
-			return svc.Deploy(ctx, &services.DeployInput{
-				Cluster:   cluster,
-				Service:   service,
-				NewConfig: cfg,
-				DryRun:    cmd.Bool("dry"),
-				Timeout:   cmd.Duration("timeout"),
-				NoWait:    cmd.Bool("no-wait"),
-			})
+			return exitError(svc.Deploy(ctx, &services.DeployInput{
+				Cluster:                   cluster,
+				Service:                   service,
+				NewConfig:                 updates.TaskConfig,
+				TaskDefinition:            cmd.String("task-definition"),
+				ServiceConfig:             updates.Service,
+				PreDeployTask:             updates.PreDeployTask,
+				Hooks:                     updates.Hooks,
+				DryRun:                    cmd.Bool("dry"),
+				Timeout:                   cmd.Duration("timeout"),
+				NoWait:                    cmd.Bool("no-wait"),
+				ForceNewDeployment:        cmd.Bool("force-new-deployment"),
+				RollbackOnFailure:         cmd.Bool("rollback-on-failure"),
+				RollbackOnInterrupt:       cmd.Bool("rollback-on-interrupt"),
+				LockOwner:                 cmd.String("lock-owner"),
+				BreakLock:                 cmd.Bool("break-lock"),
+				UpdateScheduledTasks:      cmd.Bool("update-scheduled-tasks"),
+				Prune:                     flagsPrune(cmd),
+				Canary:                    flagsCanary(cmd),
+				Confirm:                   flagsConfirm(cmd, readUpdates && cmd.String("updates") == "-"),
+				CodeDeployApplication:     cmd.String("codedeploy-application"),
+				CodeDeployDeploymentGroup: cmd.String("codedeploy-deployment-group"),
+			}))
 		},
 	}
 
-	err := app.Run(context.Background(), os.Args)
+	// a second signal gets the default behavior back and kills us right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := app.Run(ctx, os.Args)
+	stop()
 	if err != nil {
 		log.Fatal(color.RedString("%s", err))
 	}
@@ -118,6 +306,189 @@ func initLogger() {
 	log.SetPrefix("")
 }
 
+func usageError(cmd *cli.Command, message string) error {
+	ec := cli.Exit(color.RedString(message), exitCodeUsage)
+	if err := cli.ShowSubcommandHelp(cmd); err != nil {
+		log.Println(color.RedString("failed to show help: %s"), err.Error())
+	}
+	return ec
+}
+
+// exitError gives failed rollouts that were rolled back, and deploys blocked
+// by a lock, their own exit codes
+func exitError(err error) error {
+	// the canary's rollback is no rollback of the service, so look for it first
+	var canaryErr *services.CanaryError
+	if errors.As(err, &canaryErr) {
+		return cli.Exit(color.RedString("%s", err), exitCodeCanaryFailed)
+	}
+	var rollbackErr *services.RollbackError
+	if errors.As(err, &rollbackErr) {
+		if rollbackErr.RollbackCause != nil {
+			return cli.Exit(color.RedString("%s", err), exitCodeRollbackFailed)
+		}
+		return cli.Exit(color.RedString("%s", err), exitCodeRolledBack)
+	}
+	var lockErr *clients.LockHeldError
+	if errors.As(err, &lockErr) {
+		return cli.Exit(color.RedString("%s", err), exitCodeLocked)
+	}
+	return err
+}
+
+// flagsTarget is the target described by the command line flags
+func flagsTarget(cmd *cli.Command) models.Target {
+	return models.Target{
+		Region:  cmd.String("region"),
+		Profile: cmd.String("profile"),
+		RoleArn: cmd.String("role-arn"),
+	}
+}
+
+// flagsConfirm is how to ask before deploying. Unless told otherwise we ask
+// when there's somebody at the terminal and stdin isn't used for the updates.
+func flagsConfirm(cmd *cli.Command, stdinUsed bool) services.ConfirmFunc {
+	if cmd.Bool("yes") {
+		return nil
+	}
+	if cmd.IsSet("confirm") && !cmd.Bool("confirm") {
+		return nil
+	}
+	if !cmd.Bool("confirm") && (stdinUsed || !isatty.IsTerminal(os.Stdin.Fd())) {
+		return nil
+	}
+	if stdinUsed {
+		return promptConfirmTerminal
+	}
+	return promptConfirm
+}
+
+// flagsRetention is which revisions to keep when pruning
+func flagsRetention(cmd *cli.Command) services.Retention {
+	return services.Retention{
+		Keep:      int(cmd.Int("keep")),
+		OlderThan: cmd.Duration("older-than"),
+		Delete:    cmd.Bool("delete-pruned"),
+	}
+}
+
+// flagsPrune is the pruning to do after a successful deploy, nil if none
+func flagsPrune(cmd *cli.Command) *services.Retention {
+	if !cmd.Bool("prune") {
+		return nil
+	}
+	retention := flagsRetention(cmd)
+	return &retention
+}
+
+// flagsCanary is the canary described by the command line flags, nil if
+// there's no canary service
+func flagsCanary(cmd *cli.Command) *services.CanaryInput {
+	if cmd.String("canary") == "" {
+		return nil
+	}
+	return &services.CanaryInput{
+		Service:         cmd.String("canary"),
+		BakeTime:        cmd.Duration("canary-bake-time"),
+		MaxStoppedTasks: int(cmd.Int("canary-max-stopped-tasks")),
+		Alarms:          cmd.StringSlice("canary-alarm"),
+	}
+}
+
+// newAWSConfig loads the AWS configuration to reach the services of the target
+func newAWSConfig(ctx context.Context, target models.Target) (aws.Config, error) {
+	var options []func(*config.LoadOptions) error
+	if target.Region != "" {
+		options = append(options, config.WithRegion(target.Region))
+	}
+	if target.Profile != "" {
+		options = append(options, config.WithSharedConfigProfile(target.Profile))
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if target.RoleArn != "" {
+		awsConfig.Credentials = aws.NewCredentialsCache(
+			stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsConfig), target.RoleArn),
+		)
+	}
+	return awsConfig, nil
+}
+
+// newDeployer sets up a deployer reaching the services of the target
+func newDeployer(ctx context.Context, cmd *cli.Command, target models.Target) (services.DeployerService, error) {
+	awsConfig, err := newAWSConfig(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	ecsClient := ecs.NewFromConfig(awsConfig)
+	deployerOptions := []services.DeployerOption{
+		services.WithAlarmClient(clients.NewAlarmClient(cloudwatch.NewFromConfig(awsConfig))),
+		services.WithCodeDeployClient(clients.NewCodeDeployClient(codedeploy.NewFromConfig(awsConfig))),
+		services.WithScheduledTaskClient(clients.NewScheduledTaskClient(eventbridge.NewFromConfig(awsConfig), scheduler.NewFromConfig(awsConfig))),
+	}
+
+	lock := cmd.String("lock")
+	switch {
+	case lock == "":
+	case lock == "tags":
+		deployerOptions = append(deployerOptions, services.WithLockStore(clients.NewTagLockStore(ecsClient)))
+	case strings.HasPrefix(lock, "dynamodb:"):
+		deployerOptions = append(deployerOptions, services.WithLockStore(clients.NewDynamoDBLockStore(dynamodb.NewFromConfig(awsConfig), strings.TrimPrefix(lock, "dynamodb:"))))
+	case strings.HasPrefix(lock, "file:"):
+		deployerOptions = append(deployerOptions, services.WithLockStore(clients.NewFileLockStore(strings.TrimPrefix(lock, "file:"))))
+	default:
+		return nil, cli.Exit(color.RedString("Unknown lock store %q, use tags, dynamodb:<table> or file:<dir>", lock), exitCodeUsage)
+	}
+
+	return services.NewDeployerService(clients.NewECSClient(ecsClient), deployerOptions...), nil
+}
+
+// defaultLockOwner tells who's deploying from this process
+func defaultLockOwner() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "unknown"
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s:%d", user, host, os.Getpid())
+}
+
+// promptConfirm asks a yes/no question on the terminal
+func promptConfirm(question string) (bool, error) {
+	return promptConfirmFrom(os.Stdin, question)
+}
+
+// promptConfirmTerminal asks a yes/no question on the controlling terminal,
+// for when stdin was used for the updates
+func promptConfirmTerminal(question string) (bool, error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false, errorx.Decorate(err, "unable to ask for confirmation, stdin has the updates and there's no terminal")
+	}
+	defer tty.Close()
+	return promptConfirmFrom(tty, question)
+}
+
+// promptConfirmFrom asks a yes/no question reading the answer from answers,
+// running out of input before answering is an error rather than a no
+func promptConfirmFrom(answers io.Reader, question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(answers).ReadString('\n')
+	if err == io.EOF && strings.TrimSpace(answer) == "" {
+		return false, errors.New("unable to ask for confirmation, there was no answer")
+	}
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
 func readConfigPayload(inputName string) ([]byte, error) {
 	if inputName == "-" {
 		return io.ReadAll(os.Stdin)
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/adroll/ecs-ship/services"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func Test_exitError(t *testing.T) {
+	rolledBack := &services.RollbackError{Cause: errors.New("the deploy failed"), TaskDefinition: "arn::task:1"}
+	cases := map[string]struct {
+		err  error
+		code int
+	}{
+		"rolled back": {rolledBack, exitCodeRolledBack},
+		"rollback failed": {
+			&services.RollbackError{Cause: errors.New("the deploy failed"), RollbackCause: errors.New("no")},
+			exitCodeRollbackFailed,
+		},
+		// only the canary was rolled back, the service wasn't touched
+		"canary failed": {
+			errorx.Decorate(&services.CanaryError{Canary: "service-canary", Cause: rolledBack}, "unable to deploy"),
+			exitCodeCanaryFailed,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var exitCoder cli.ExitCoder
+			assert.ErrorAs(t, exitError(c.err), &exitCoder)
+			assert.Equal(t, c.code, exitCoder.ExitCode())
+		})
+	}
+}
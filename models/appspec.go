@@ -0,0 +1,125 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// AppSpec is the CodeDeploy application specification for an ECS service
+type AppSpec struct {
+	Version   json.Number       `json:"version"`
+	Resources []AppSpecResource `json:"Resources"`
+}
+
+// AppSpecResource is the service CodeDeploy deploys
+type AppSpecResource struct {
+	TargetService AppSpecTargetService `json:"TargetService"`
+}
+
+// AppSpecTargetService describes the replacement task set of the service
+type AppSpecTargetService struct {
+	Type       string            `json:"Type"`
+	Properties AppSpecProperties `json:"Properties"`
+}
+
+// AppSpecProperties are the settings of the replacement task set
+type AppSpecProperties struct {
+	TaskDefinition           string                            `json:"TaskDefinition"`
+	LoadBalancerInfo         *AppSpecLoadBalancerInfo          `json:"LoadBalancerInfo,omitempty"`
+	PlatformVersion          *string                           `json:"PlatformVersion,omitempty"`
+	NetworkConfiguration     *AppSpecNetworkConfiguration      `json:"NetworkConfiguration,omitempty"`
+	CapacityProviderStrategy []AppSpecCapacityProviderStrategy `json:"CapacityProviderStrategy,omitempty"`
+}
+
+// AppSpecLoadBalancerInfo is the container that receives the traffic
+type AppSpecLoadBalancerInfo struct {
+	ContainerName string `json:"ContainerName"`
+	ContainerPort int32  `json:"ContainerPort"`
+}
+
+// AppSpecNetworkConfiguration is the awsvpc configuration of the task set
+type AppSpecNetworkConfiguration struct {
+	AwsvpcConfiguration AppSpecAwsvpcConfiguration `json:"AwsvpcConfiguration"`
+}
+
+// AppSpecAwsvpcConfiguration lists the subnets and security groups of the task set
+type AppSpecAwsvpcConfiguration struct {
+	Subnets        []string `json:"Subnets"`
+	SecurityGroups []string `json:"SecurityGroups,omitempty"`
+	AssignPublicIP string   `json:"AssignPublicIp,omitempty"`
+}
+
+// AppSpecCapacityProviderStrategy is an item of the capacity provider strategy of the task set
+type AppSpecCapacityProviderStrategy struct {
+	CapacityProvider string `json:"CapacityProvider"`
+	Weight           int32  `json:"Weight"`
+	Base             int32  `json:"Base"`
+}
+
+// NewAppSpec describes a deployment of the task definition to the service.
+// The network configuration and capacity provider strategy come from the
+// service, unless the patch changes them, because CodeDeploy sets them on the
+// replacement task set instead of the service.
+func NewAppSpec(service *types.Service, taskDefinition string, patch *ecs.UpdateServiceInput) *AppSpec {
+	properties := AppSpecProperties{
+		TaskDefinition:  taskDefinition,
+		PlatformVersion: service.PlatformVersion,
+	}
+
+	if len(service.LoadBalancers) > 0 {
+		loadBalancer := service.LoadBalancers[0]
+		properties.LoadBalancerInfo = &AppSpecLoadBalancerInfo{
+			ContainerName: aws.ToString(loadBalancer.ContainerName),
+			ContainerPort: aws.ToInt32(loadBalancer.ContainerPort),
+		}
+	}
+
+	networkConfiguration := service.NetworkConfiguration
+	if patch != nil && patch.NetworkConfiguration != nil {
+		networkConfiguration = patch.NetworkConfiguration
+	}
+	if networkConfiguration != nil && networkConfiguration.AwsvpcConfiguration != nil {
+		awsvpc := networkConfiguration.AwsvpcConfiguration
+		properties.NetworkConfiguration = &AppSpecNetworkConfiguration{
+			AwsvpcConfiguration: AppSpecAwsvpcConfiguration{
+				Subnets:        awsvpc.Subnets,
+				SecurityGroups: awsvpc.SecurityGroups,
+				AssignPublicIP: string(awsvpc.AssignPublicIp),
+			},
+		}
+	}
+
+	strategy := service.CapacityProviderStrategy
+	if patch != nil && patch.CapacityProviderStrategy != nil {
+		strategy = patch.CapacityProviderStrategy
+	}
+	for _, item := range strategy {
+		properties.CapacityProviderStrategy = append(properties.CapacityProviderStrategy, AppSpecCapacityProviderStrategy{
+			CapacityProvider: aws.ToString(item.CapacityProvider),
+			Weight:           item.Weight,
+			Base:             item.Base,
+		})
+	}
+
+	return &AppSpec{
+		Version: "0.0",
+		Resources: []AppSpecResource{{
+			TargetService: AppSpecTargetService{
+				Type:       "AWS::ECS::Service",
+				Properties: properties,
+			},
+		}},
+	}
+}
+
+// Content is the AppSpec as CodeDeploy takes it
+func (appSpec *AppSpec) Content() (string, error) {
+	content, err := json.Marshal(appSpec)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,76 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewAppSpec(t *testing.T) {
+	service := &types.Service{
+		PlatformVersion: aws.String("LATEST"),
+		LoadBalancers: []types.LoadBalancer{
+			{ContainerName: aws.String("web"), ContainerPort: aws.Int32(8080)},
+		},
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{
+				Subnets:        []string{"subnet-a"},
+				SecurityGroups: []string{"sg-a"},
+				AssignPublicIp: types.AssignPublicIpDisabled,
+			},
+		},
+	}
+
+	content, err := models.NewAppSpec(service, "arn::task:2", &ecs.UpdateServiceInput{}).Content()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"version": 0.0,
+		"Resources": [{
+			"TargetService": {
+				"Type": "AWS::ECS::Service",
+				"Properties": {
+					"TaskDefinition": "arn::task:2",
+					"LoadBalancerInfo": {"ContainerName": "web", "ContainerPort": 8080},
+					"PlatformVersion": "LATEST",
+					"NetworkConfiguration": {
+						"AwsvpcConfiguration": {
+							"Subnets": ["subnet-a"],
+							"SecurityGroups": ["sg-a"],
+							"AssignPublicIp": "DISABLED"
+						}
+					}
+				}
+			}
+		}]
+	}`, content)
+}
+
+func Test_NewAppSpec_Patched(t *testing.T) {
+	service := &types.Service{
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+		CapacityProviderStrategy: []types.CapacityProviderStrategyItem{
+			{CapacityProvider: aws.String("FARGATE"), Weight: 1},
+		},
+	}
+	patch := &ecs.UpdateServiceInput{
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-b"}},
+		},
+		CapacityProviderStrategy: []types.CapacityProviderStrategyItem{
+			{CapacityProvider: aws.String("FARGATE_SPOT"), Weight: 3, Base: 1},
+		},
+	}
+
+	properties := models.NewAppSpec(service, "arn::task:2", patch).Resources[0].TargetService.Properties
+	assert.Nil(t, properties.LoadBalancerInfo)
+	assert.Equal(t, []string{"subnet-b"}, properties.NetworkConfiguration.AwsvpcConfiguration.Subnets)
+	assert.Equal(t, []models.AppSpecCapacityProviderStrategy{
+		{CapacityProvider: "FARGATE_SPOT", Weight: 3, Base: 1},
+	}, properties.CapacityProviderStrategy)
+}
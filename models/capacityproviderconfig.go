@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// CapacityProviderConfig represents an item of a capacity provider strategy
+type CapacityProviderConfig struct {
+	CapacityProvider string `json:"capacityProvider" yaml:"capacityProvider"`
+	Weight           int32  `json:"weight" yaml:"weight"`
+	Base             int32  `json:"base" yaml:"base"`
+}
+
+// capacityProviderStrategy builds the strategy items ECS expects out of a config
+func capacityProviderStrategy(configs []CapacityProviderConfig) []types.CapacityProviderStrategyItem {
+	strategy := make([]types.CapacityProviderStrategyItem, 0, len(configs))
+	for _, config := range configs {
+		provider := config.CapacityProvider
+		strategy = append(strategy, types.CapacityProviderStrategyItem{
+			CapacityProvider: &provider,
+			Weight:           config.Weight,
+			Base:             config.Base,
+		})
+	}
+	return strategy
+}
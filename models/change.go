@@ -0,0 +1,459 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// ChangeKind describes how a value changed
+type ChangeKind int
+
+const (
+	// Modified means the value is present on both sides but differs
+	Modified ChangeKind = iota
+	// Added means the value is only present on the new side
+	Added
+	// Removed means the value is only present on the old side
+	Removed
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+// Change is a node in a tree of differences between two values. Leaves hold
+// the old and new values, inner nodes hold the changes of their fields,
+// map entries or slice elements.
+type Change struct {
+	Name     string
+	Kind     ChangeKind
+	Was      interface{}
+	IsNow    interface{}
+	Children []*Change
+}
+
+// sliceKeys holds the natural identifier of slice elements, so slices of
+// these types are diffed as maps instead of by position
+var sliceKeys = map[reflect.Type]func(reflect.Value) string{}
+
+// unorderedFields holds the string slice fields whose order doesn't matter,
+// so they are diffed as sets of added and removed entries
+var unorderedFields = map[reflect.Type]map[string]bool{
+	reflect.TypeOf(types.AwsVpcConfiguration{}): {"Subnets": true, "SecurityGroups": true},
+}
+
+func keyBy[T any](key func(T) string) {
+	sliceKeys[reflect.TypeOf(*new(T))] = func(value reflect.Value) string {
+		return key(value.Interface().(T))
+	}
+}
+
+func init() {
+	keyBy(func(def types.ContainerDefinition) string { return aws.ToString(def.Name) })
+	keyBy(func(pair types.KeyValuePair) string { return aws.ToString(pair.Name) })
+	keyBy(func(secret types.Secret) string { return aws.ToString(secret.Name) })
+	keyBy(func(mapping types.PortMapping) string {
+		return fmt.Sprintf("%d/%s", aws.ToInt32(mapping.ContainerPort), mapping.Protocol)
+	})
+	keyBy(func(point types.MountPoint) string { return aws.ToString(point.ContainerPath) })
+	keyBy(func(from types.VolumeFrom) string { return aws.ToString(from.SourceContainer) })
+	keyBy(func(volume types.Volume) string { return aws.ToString(volume.Name) })
+	keyBy(func(tag types.Tag) string { return aws.ToString(tag.Key) })
+	keyBy(func(ulimit types.Ulimit) string { return string(ulimit.Name) })
+	keyBy(func(requirement types.ResourceRequirement) string { return string(requirement.Type) })
+	keyBy(func(dependency types.ContainerDependency) string { return aws.ToString(dependency.ContainerName) })
+	keyBy(func(control types.SystemControl) string { return aws.ToString(control.Namespace) })
+	keyBy(func(file types.EnvironmentFile) string { return aws.ToString(file.Value) })
+	keyBy(func(entry types.HostEntry) string { return aws.ToString(entry.Hostname) })
+	keyBy(func(device types.Device) string { return aws.ToString(device.HostPath) })
+	keyBy(func(tmpfs types.Tmpfs) string { return aws.ToString(tmpfs.ContainerPath) })
+	keyBy(func(accelerator types.InferenceAccelerator) string { return aws.ToString(accelerator.DeviceName) })
+	keyBy(func(item types.CapacityProviderStrategyItem) string { return aws.ToString(item.CapacityProvider) })
+	keyBy(func(constraint types.TaskDefinitionPlacementConstraint) string {
+		return fmt.Sprintf("%s:%s", constraint.Type, aws.ToString(constraint.Expression))
+	})
+}
+
+// CompareTaskDefinitions computes every change between two task definitions
+func CompareTaskDefinitions(was *ecs.RegisterTaskDefinitionInput, isNow *ecs.RegisterTaskDefinitionInput) *Change {
+	return Compare(was, isNow)
+}
+
+// Compare computes the tree of changes between two values of the same type
+func Compare(was interface{}, isNow interface{}) *Change {
+	root := compareValues("", reflect.ValueOf(was), reflect.ValueOf(isNow))
+	if root == nil {
+		return &Change{}
+	}
+	return root
+}
+
+func compareValues(name string, was reflect.Value, isNow reflect.Value) *Change {
+	if !was.IsValid() || !isNow.IsValid() {
+		if was.IsValid() == isNow.IsValid() {
+			return nil
+		}
+		return &Change{Name: name, Kind: Modified, Was: interfaceOf(was), IsNow: interfaceOf(isNow)}
+	}
+
+	switch was.Kind() {
+	case reflect.Interface:
+		if was.IsNil() && isNow.IsNil() {
+			return nil
+		}
+		// each side can hold a value of any type, only the same types compare field by field
+		if was.IsNil() || isNow.IsNil() || was.Elem().Type() != isNow.Elem().Type() {
+			return &Change{Name: name, Kind: Modified, Was: interfaceOf(was), IsNow: interfaceOf(isNow)}
+		}
+		return compareValues(name, was.Elem(), isNow.Elem())
+	case reflect.Ptr:
+		if was.IsNil() && isNow.IsNil() {
+			return nil
+		}
+		if was.IsNil() || isNow.IsNil() {
+			kind := Modified
+			if isComposite(was.Type().Elem()) {
+				kind = Added
+				if isNow.IsNil() {
+					kind = Removed
+				}
+			}
+			return &Change{Name: name, Kind: kind, Was: interfaceOf(was), IsNow: interfaceOf(isNow)}
+		}
+		return compareValues(name, was.Elem(), isNow.Elem())
+	case reflect.Struct:
+		var children []*Change
+		for i := 0; i < was.NumField(); i++ {
+			field := was.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if unorderedFields[was.Type()][field.Name] {
+				if child := compareSets(lowerFirst(field.Name), was.Field(i), isNow.Field(i)); child != nil {
+					children = append(children, child)
+				}
+				continue
+			}
+			if child := compareValues(lowerFirst(field.Name), was.Field(i), isNow.Field(i)); child != nil {
+				children = append(children, child)
+			}
+		}
+		return parent(name, children)
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, key := range append(was.MapKeys(), isNow.MapKeys()...) {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+		names := make([]string, 0, len(keys))
+		for keyName := range keys {
+			names = append(names, keyName)
+		}
+		sort.Strings(names)
+		var children []*Change
+		for _, keyName := range names {
+			key := keys[keyName]
+			if child := compareEntries(fmt.Sprintf("[%q]", keyName), was.MapIndex(key), isNow.MapIndex(key)); child != nil {
+				children = append(children, child)
+			}
+		}
+		return parent(name, children)
+	case reflect.Slice, reflect.Array:
+		if was.Len() == 0 && isNow.Len() == 0 {
+			return nil
+		}
+		if key, ok := sliceKeys[was.Type().Elem()]; ok {
+			if children, ok := compareKeyedSlices(key, was, isNow); ok {
+				return parent(name, children)
+			}
+		}
+		if !isComposite(was.Type().Elem()) {
+			if reflect.DeepEqual(was.Interface(), isNow.Interface()) {
+				return nil
+			}
+			return &Change{Name: name, Kind: Modified, Was: was.Interface(), IsNow: isNow.Interface()}
+		}
+		var children []*Change
+		for i := 0; i < was.Len() || i < isNow.Len(); i++ {
+			var wasItem, isNowItem reflect.Value
+			if i < was.Len() {
+				wasItem = was.Index(i)
+			}
+			if i < isNow.Len() {
+				isNowItem = isNow.Index(i)
+			}
+			if child := compareEntries(fmt.Sprintf("[%d]", i), wasItem, isNowItem); child != nil {
+				children = append(children, child)
+			}
+		}
+		return parent(name, children)
+	default:
+		if was.Interface() == isNow.Interface() {
+			return nil
+		}
+		return &Change{Name: name, Kind: Modified, Was: was.Interface(), IsNow: isNow.Interface()}
+	}
+}
+
+// compareEntries compares map entries or slice elements that might be
+// missing on either side
+func compareEntries(name string, was reflect.Value, isNow reflect.Value) *Change {
+	if was.IsValid() && isNow.IsValid() {
+		return compareValues(name, was, isNow)
+	}
+	if !isComposite(typeOf(was, isNow)) {
+		return &Change{Name: name, Kind: Modified, Was: interfaceOf(was), IsNow: interfaceOf(isNow)}
+	}
+	if isNow.IsValid() {
+		return &Change{Name: name, Kind: Added, IsNow: interfaceOf(isNow)}
+	}
+	return &Change{Name: name, Kind: Removed, Was: interfaceOf(was)}
+}
+
+// compareKeyedSlices compares slices by the natural identifier of their
+// elements, it fails when an identifier is not unique
+func compareKeyedSlices(key func(reflect.Value) string, was reflect.Value, isNow reflect.Value) ([]*Change, bool) {
+	wasByKey, wasKeys, ok := indexSlice(key, was)
+	if !ok {
+		return nil, false
+	}
+	isNowByKey, isNowKeys, ok := indexSlice(key, isNow)
+	if !ok {
+		return nil, false
+	}
+	keys := wasKeys
+	for _, k := range isNowKeys {
+		if _, prs := wasByKey[k]; !prs {
+			keys = append(keys, k)
+		}
+	}
+	var children []*Change
+	for _, k := range keys {
+		if child := compareEntries(fmt.Sprintf("[%q]", k), wasByKey[k], isNowByKey[k]); child != nil {
+			children = append(children, child)
+		}
+	}
+	return children, true
+}
+
+// compareSets compares string slices ignoring the order of their entries
+func compareSets(name string, was reflect.Value, isNow reflect.Value) *Change {
+	wasSet := make(map[string]struct{}, was.Len())
+	for i := 0; i < was.Len(); i++ {
+		wasSet[was.Index(i).String()] = struct{}{}
+	}
+	isNowSet := make(map[string]struct{}, isNow.Len())
+	for i := 0; i < isNow.Len(); i++ {
+		isNowSet[isNow.Index(i).String()] = struct{}{}
+	}
+	var children []*Change
+	for i := 0; i < was.Len(); i++ {
+		if _, prs := isNowSet[was.Index(i).String()]; !prs {
+			children = append(children, &Change{Kind: Removed, Was: was.Index(i).Interface()})
+		}
+	}
+	for i := 0; i < isNow.Len(); i++ {
+		if _, prs := wasSet[isNow.Index(i).String()]; !prs {
+			children = append(children, &Change{Kind: Added, IsNow: isNow.Index(i).Interface()})
+		}
+	}
+	return parent(name, children)
+}
+
+func indexSlice(key func(reflect.Value) string, slice reflect.Value) (map[string]reflect.Value, []string, bool) {
+	byKey := make(map[string]reflect.Value, slice.Len())
+	keys := make([]string, 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		k := key(slice.Index(i))
+		if _, prs := byKey[k]; prs {
+			return nil, nil, false
+		}
+		byKey[k] = slice.Index(i)
+		keys = append(keys, k)
+	}
+	return byKey, keys, true
+}
+
+func parent(name string, children []*Change) *Change {
+	if len(children) == 0 {
+		return nil
+	}
+	return &Change{Name: name, Kind: Modified, Children: children}
+}
+
+func isComposite(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+func typeOf(values ...reflect.Value) reflect.Type {
+	for _, value := range values {
+		if value.IsValid() {
+			return value.Type()
+		}
+	}
+	return nil
+}
+
+func interfaceOf(value reflect.Value) interface{} {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
+
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// Empty check if there's no change at all
+func (change *Change) Empty() bool {
+	return change == nil || change.Kind == Modified && len(change.Children) == 0 && change.Was == nil && change.IsNow == nil
+}
+
+// Changed tells whether the field or entry with the given name is among the
+// changes right below this one
+func (change *Change) Changed(name string) bool {
+	if change == nil {
+		return false
+	}
+	for _, child := range change.Children {
+		if child.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (change *Change) String() string {
+	if change.Empty() {
+		return ""
+	}
+	var lines []string
+	change.describe("", &lines)
+	return strings.Join(lines, "\n")
+}
+
+func (change *Change) describe(prefix string, lines *[]string) {
+	path := joinPath(prefix, change.Name)
+	switch {
+	case len(change.Children) > 0:
+		for _, child := range change.Children {
+			child.describe(path, lines)
+		}
+	case change.Kind == Added:
+		*lines = append(*lines, fmt.Sprintf("%s was added: %s", path, formatValue(reflect.ValueOf(change.IsNow))))
+	case change.Kind == Removed:
+		*lines = append(*lines, fmt.Sprintf("%s was removed: %s", path, formatValue(reflect.ValueOf(change.Was))))
+	default:
+		*lines = append(*lines, fmt.Sprintf(
+			"%s was: %s and now is: %s",
+			path,
+			formatValue(reflect.ValueOf(change.Was)),
+			formatValue(reflect.ValueOf(change.IsNow)),
+		))
+	}
+}
+
+// changeJSON is a single changed value, as Change renders it in JSON
+type changeJSON struct {
+	Path  string      `json:"path"`
+	Kind  string      `json:"kind"`
+	Was   interface{} `json:"was,omitempty"`
+	IsNow interface{} `json:"isNow,omitempty"`
+}
+
+// MarshalJSON renders the change as the flat list of the values that changed,
+// with the same paths String uses
+func (change *Change) MarshalJSON() ([]byte, error) {
+	values := []changeJSON{}
+	if !change.Empty() {
+		change.flatten("", &values)
+	}
+	return json.Marshal(values)
+}
+
+func (change *Change) flatten(prefix string, values *[]changeJSON) {
+	path := joinPath(prefix, change.Name)
+	if len(change.Children) > 0 {
+		for _, child := range change.Children {
+			child.flatten(path, values)
+		}
+		return
+	}
+	*values = append(*values, changeJSON{Path: path, Kind: change.Kind.String(), Was: change.Was, IsNow: change.IsNow})
+}
+
+func joinPath(prefix string, name string) string {
+	if prefix == "" || name == "" || strings.HasPrefix(name, "[") {
+		return prefix + name
+	}
+	return prefix + "." + name
+}
+
+// formatValue renders a value in a compact way, omitting empty fields
+func formatValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "<nil>"
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return "<nil>"
+		}
+		return formatValue(value.Elem())
+	case reflect.String:
+		return fmt.Sprintf("%q", value.String())
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() || value.Field(i).IsZero() {
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s", lowerFirst(field.Name), formatValue(value.Field(i))))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, formatValue(value.Index(i)))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		items := make([]string, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%s: %s", formatValue(key), formatValue(value.MapIndex(key))))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
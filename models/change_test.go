@@ -0,0 +1,190 @@
+package models_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Change_Empty(t *testing.T) {
+	var change *models.Change
+	assert.True(t, change.Empty())
+	assert.True(t, (&models.Change{}).Empty())
+	assert.Equal(t, "", change.String())
+}
+
+func Test_Change_Changed(t *testing.T) {
+	change := models.Compare(
+		&ecs.UpdateServiceInput{DesiredCount: aws.Int32(2), ForceNewDeployment: true},
+		&ecs.UpdateServiceInput{DesiredCount: aws.Int32(3), ForceNewDeployment: true},
+	)
+	assert.True(t, change.Changed("desiredCount"))
+	assert.False(t, change.Changed("forceNewDeployment"))
+	var nothing *models.Change
+	assert.False(t, nothing.Changed("desiredCount"))
+}
+
+func Test_CompareTaskDefinitions_NoChanges(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		Cpu: aws.String("256"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web"), Environment: []types.KeyValuePair{}},
+		},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		Cpu: aws.String("256"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.True(t, change.Empty())
+}
+
+func Test_CompareTaskDefinitions_Scalars(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256"), NetworkMode: types.NetworkModeBridge}
+	isNow := &ecs.RegisterTaskDefinitionInput{Cpu: aws.String("512"), Memory: aws.String("1024"), NetworkMode: types.NetworkModeAwsvpc}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.False(t, change.Empty())
+	assert.Equal(t, []string{
+		"cpu was: \"256\" and now is: \"512\"",
+		"memory was: <nil> and now is: \"1024\"",
+		"networkMode was: \"bridge\" and now is: \"awsvpc\"",
+	}, strings.Split(change.String(), "\n"))
+}
+
+func Test_CompareTaskDefinitions_KeyedSlices(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{
+			{
+				Name:  aws.String("web"),
+				Image: aws.String("web:1"),
+				Environment: []types.KeyValuePair{
+					{Name: aws.String("A"), Value: aws.String("1")},
+					{Name: aws.String("B"), Value: aws.String("2")},
+				},
+			},
+			{Name: aws.String("sidecar"), Cpu: 10},
+		},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("sidecar"), Cpu: 20},
+			{
+				Name:  aws.String("web"),
+				Image: aws.String("web:2"),
+				Environment: []types.KeyValuePair{
+					{Name: aws.String("B"), Value: aws.String("2")},
+					{Name: aws.String("C"), Value: aws.String("3")},
+				},
+				PortMappings: []types.PortMapping{{ContainerPort: aws.Int32(80), Protocol: types.TransportProtocolTcp}},
+			},
+		},
+	}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.Equal(t, []string{
+		"containerDefinitions[\"web\"].environment[\"A\"] was removed: {name: \"A\", value: \"1\"}",
+		"containerDefinitions[\"web\"].environment[\"C\"] was added: {name: \"C\", value: \"3\"}",
+		"containerDefinitions[\"web\"].image was: \"web:1\" and now is: \"web:2\"",
+		"containerDefinitions[\"web\"].portMappings[\"80/tcp\"] was added: {containerPort: 80, protocol: \"tcp\"}",
+		"containerDefinitions[\"sidecar\"].cpu was: 10 and now is: 20",
+	}, strings.Split(change.String(), "\n"))
+}
+
+func Test_CompareTaskDefinitions_ContainerAddedAndRemoved(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{Name: aws.String("old")}},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{Name: aws.String("new"), Image: aws.String("img")}},
+	}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.Len(t, change.Children, 1)
+	containers := change.Children[0]
+	assert.Equal(t, "containerDefinitions", containers.Name)
+	assert.Equal(t, models.Removed, containers.Children[0].Kind)
+	assert.Equal(t, models.Added, containers.Children[1].Kind)
+	assert.Equal(t, []string{
+		"containerDefinitions[\"old\"] was removed: {name: \"old\"}",
+		"containerDefinitions[\"new\"] was added: {image: \"img\", name: \"new\"}",
+	}, strings.Split(change.String(), "\n"))
+}
+
+func Test_CompareTaskDefinitions_MapsAndPlainSlices(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{
+			Name:         aws.String("web"),
+			Command:      []string{"run", "--fast"},
+			DockerLabels: map[string]string{"team": "a", "old": "x"},
+		}},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{
+			Name:         aws.String("web"),
+			Command:      []string{"run"},
+			DockerLabels: map[string]string{"team": "b"},
+		}},
+	}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.Equal(t, []string{
+		"containerDefinitions[\"web\"].command was: [\"run\", \"--fast\"] and now is: [\"run\"]",
+		"containerDefinitions[\"web\"].dockerLabels[\"old\"] was: \"x\" and now is: <nil>",
+		"containerDefinitions[\"web\"].dockerLabels[\"team\"] was: \"a\" and now is: \"b\"",
+	}, strings.Split(change.String(), "\n"))
+}
+
+func Test_Compare_InterfacesOfDifferentTypes(t *testing.T) {
+	type setting struct {
+		Value interface{}
+	}
+	change := models.Compare(
+		[]setting{{Value: struct{ Size int }{Size: 1}}, {Value: nil}},
+		[]setting{{Value: map[string]int{"size": 1}}, {Value: "small"}},
+	)
+	assert.Equal(t, []string{
+		"[0].value was: {size: 1} and now is: {\"size\": 1}",
+		"[1].value was: <nil> and now is: \"small\"",
+	}, strings.Split(change.String(), "\n"))
+}
+
+func Test_CompareTaskDefinitions_DuplicateKeysFallBackToPositions(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		Tags: []types.Tag{{Key: aws.String("k"), Value: aws.String("1")}, {Key: aws.String("k"), Value: aws.String("2")}},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		Tags: []types.Tag{{Key: aws.String("k"), Value: aws.String("1")}, {Key: aws.String("k"), Value: aws.String("3")}},
+	}
+	change := models.CompareTaskDefinitions(was, isNow)
+	assert.Equal(t, "tags[1].value was: \"2\" and now is: \"3\"", change.String())
+}
+
+func Test_Change_MarshalJSON(t *testing.T) {
+	was := &ecs.RegisterTaskDefinitionInput{
+		Cpu: aws.String("256"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web"), Image: aws.String("web:1")},
+		},
+	}
+	isNow := &ecs.RegisterTaskDefinitionInput{
+		Cpu: aws.String("512"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web"), Image: aws.String("web:2")},
+		},
+	}
+	data, err := json.Marshal(models.CompareTaskDefinitions(was, isNow))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"path": "containerDefinitions[\"web\"].image", "kind": "modified", "was": "web:1", "isNow": "web:2"},
+		{"path": "cpu", "kind": "modified", "was": "256", "isNow": "512"}
+	]`, string(data))
+
+	data, err = json.Marshal(&models.Change{})
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", string(data))
+}
@@ -12,8 +12,7 @@ type ContainerConfig struct {
 }
 
 // ApplyTo apply a config to a container definition
-func (config *ContainerConfig) ApplyTo(input *types.ContainerDefinition) (types.ContainerDefinition, *ContainerConfigDiff) {
-	diff := &ContainerConfigDiff{}
+func (config *ContainerConfig) ApplyTo(input *types.ContainerDefinition) types.ContainerDefinition {
 	newDef := types.ContainerDefinition{
 		Command:                input.Command,
 		Cpu:                    input.Cpu,
@@ -56,11 +55,11 @@ func (config *ContainerConfig) ApplyTo(input *types.ContainerDefinition) (types.
 		VolumesFrom:            input.VolumesFrom,
 		WorkingDirectory:       input.WorkingDirectory,
 	}
-	updateInt(&newDef.Cpu, config.CPU, func(val int32) { newDef.Cpu = val }, diff.ChangeCPU)
-	updateString(newDef.Image, config.Image, func(val string) { newDef.Image = &val }, diff.ChangeImage)
+	updateInt(&newDef.Cpu, config.CPU, func(val int32) { newDef.Cpu = val })
+	updateString(newDef.Image, config.Image, func(val string) { newDef.Image = &val })
 	// FIXME: We should have UpdateIntPtr instead
-	updateInt(newDef.Memory, config.Memory, func(val int32) { newDef.Memory = &val }, diff.ChangeMemory)
-	updateInt(newDef.MemoryReservation, config.MemoryReservation, func(val int32) { newDef.MemoryReservation = &val }, diff.ChangeMemoryReservation)
+	updateInt(newDef.Memory, config.Memory, func(val int32) { newDef.Memory = &val })
+	updateInt(newDef.MemoryReservation, config.MemoryReservation, func(val int32) { newDef.MemoryReservation = &val })
 
 	newEnvironment := make([]types.KeyValuePair, 0)
 	used := make(map[string]struct{})
@@ -70,7 +69,6 @@ func (config *ContainerConfig) ApplyTo(input *types.ContainerDefinition) (types.
 		if value, prs := config.Environment[*pair.Name]; prs {
 			valueCopy := value[:]
 			newEnvironment = append(newEnvironment, types.KeyValuePair{Name: pair.Name, Value: &valueCopy})
-			diff.ChangeEnvironment(*pair.Name, pair.Value, &valueCopy)
 			used[*pair.Name] = usedFlag
 		} else {
 			newEnvironment = append(newEnvironment, pair)
@@ -85,9 +83,8 @@ func (config *ContainerConfig) ApplyTo(input *types.ContainerDefinition) (types.
 		nameCopy := name[:]
 		valueCopy := value[:]
 		newEnvironment = append(newEnvironment, types.KeyValuePair{Name: &nameCopy, Value: &valueCopy})
-		diff.ChangeEnvironment(name, nil, &valueCopy)
 	}
 	newDef.Environment = newEnvironment
 
-	return newDef, diff
+	return newDef
 }
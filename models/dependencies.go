@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortWaves groups items in waves, so every item comes in a later wave than
+// all the items it depends on. It fails on unknown dependencies and cycles.
+func SortWaves[T any](items []T, name func(T) string, dependsOn func(T) []string) ([][]T, error) {
+	known := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		known[name(item)] = struct{}{}
+	}
+	for _, item := range items {
+		for _, dependency := range dependsOn(item) {
+			if _, prs := known[dependency]; !prs {
+				return nil, fmt.Errorf("%s depends on %s, which is unknown", name(item), dependency)
+			}
+		}
+	}
+
+	placed := make(map[string]struct{}, len(items))
+	pending := items
+	var waves [][]T
+	for len(pending) > 0 {
+		var wave, rest []T
+		for _, item := range pending {
+			if allPlaced(dependsOn(item), placed) {
+				wave = append(wave, item)
+			} else {
+				rest = append(rest, item)
+			}
+		}
+		if len(wave) == 0 {
+			return nil, fmt.Errorf("there's a dependency cycle: %s", findCycle(rest, name, dependsOn, placed))
+		}
+		for _, item := range wave {
+			placed[name(item)] = struct{}{}
+		}
+		waves = append(waves, wave)
+		pending = rest
+	}
+	return waves, nil
+}
+
+func allPlaced(names []string, placed map[string]struct{}) bool {
+	for _, name := range names {
+		if _, prs := placed[name]; !prs {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle walks the dependencies that couldn't be placed until it comes
+// back to an item it already visited, every one of them is part of or
+// depends on a cycle so the walk always finds one
+func findCycle[T any](items []T, name func(T) string, dependsOn func(T) []string, placed map[string]struct{}) string {
+	byName := make(map[string]T, len(items))
+	for _, item := range items {
+		byName[name(item)] = item
+	}
+	var path []string
+	visited := make(map[string]int)
+	current := name(items[0])
+	for {
+		if start, prs := visited[current]; prs {
+			return strings.Join(append(path[start:], current), " -> ")
+		}
+		visited[current] = len(path)
+		path = append(path, current)
+		for _, dependency := range dependsOn(byName[current]) {
+			if _, prs := placed[dependency]; !prs {
+				current = dependency
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type node struct {
+	name      string
+	dependsOn []string
+}
+
+func sortNodes(nodes ...node) ([][]node, error) {
+	return models.SortWaves(
+		nodes,
+		func(n node) string { return n.name },
+		func(n node) []string { return n.dependsOn },
+	)
+}
+
+func Test_SortWaves(t *testing.T) {
+	waves, err := sortNodes(
+		node{name: "cron", dependsOn: []string{"workers"}},
+		node{name: "workers", dependsOn: []string{"api"}},
+		node{name: "api"},
+		node{name: "web"},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]node{
+		{{name: "api"}, {name: "web"}},
+		{{name: "workers", dependsOn: []string{"api"}}},
+		{{name: "cron", dependsOn: []string{"workers"}}},
+	}, waves)
+}
+
+func Test_SortWaves_UnknownDependency(t *testing.T) {
+	_, err := sortNodes(node{name: "api", dependsOn: []string{"db"}})
+	assert.Error(t, err)
+	assert.Equal(t, "api depends on db, which is unknown", err.Error())
+}
+
+func Test_SortWaves_Cycle(t *testing.T) {
+	_, err := sortNodes(
+		node{name: "api"},
+		node{name: "cron", dependsOn: []string{"workers"}},
+		node{name: "workers", dependsOn: []string{"api", "scheduler"}},
+		node{name: "scheduler", dependsOn: []string{"workers"}},
+	)
+	assert.Error(t, err)
+	assert.Equal(t, "there's a dependency cycle: workers -> scheduler -> workers", err.Error())
+}
+
+func Test_SortWaves_SelfDependency(t *testing.T) {
+	_, err := sortNodes(node{name: "api", dependsOn: []string{"api"}})
+	assert.Error(t, err)
+	assert.Equal(t, "there's a dependency cycle: api -> api", err.Error())
+}
@@ -0,0 +1,53 @@
+package models
+
+import "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+// DeploymentConfig represents changes we can make to the deployment settings of a service
+type DeploymentConfig struct {
+	MinimumHealthyPercent    *int32                `json:"minimumHealthyPercent" yaml:"minimumHealthyPercent"`
+	MaximumPercent           *int32                `json:"maximumPercent" yaml:"maximumPercent"`
+	DeploymentCircuitBreaker *CircuitBreakerConfig `json:"deploymentCircuitBreaker" yaml:"deploymentCircuitBreaker"`
+}
+
+// CircuitBreakerConfig represents changes we can make to the deployment circuit breaker
+type CircuitBreakerConfig struct {
+	Enable   *bool `json:"enable" yaml:"enable"`
+	Rollback *bool `json:"rollback" yaml:"rollback"`
+}
+
+// ApplyTo apply a config to the deployment configuration of a service
+func (config *DeploymentConfig) ApplyTo(input *types.DeploymentConfiguration) *types.DeploymentConfiguration {
+	newConfig := &types.DeploymentConfiguration{}
+	if input != nil {
+		newConfig.Alarms = input.Alarms
+		newConfig.DeploymentCircuitBreaker = input.DeploymentCircuitBreaker
+		newConfig.MaximumPercent = input.MaximumPercent
+		newConfig.MinimumHealthyPercent = input.MinimumHealthyPercent
+	}
+	if config.MinimumHealthyPercent != nil {
+		newConfig.MinimumHealthyPercent = config.MinimumHealthyPercent
+	}
+	if config.MaximumPercent != nil {
+		newConfig.MaximumPercent = config.MaximumPercent
+	}
+	if config.DeploymentCircuitBreaker != nil {
+		newConfig.DeploymentCircuitBreaker = config.DeploymentCircuitBreaker.ApplyTo(newConfig.DeploymentCircuitBreaker)
+	}
+	return newConfig
+}
+
+// ApplyTo apply a config to a deployment circuit breaker
+func (config *CircuitBreakerConfig) ApplyTo(input *types.DeploymentCircuitBreaker) *types.DeploymentCircuitBreaker {
+	newBreaker := &types.DeploymentCircuitBreaker{}
+	if input != nil {
+		newBreaker.Enable = input.Enable
+		newBreaker.Rollback = input.Rollback
+	}
+	if config.Enable != nil {
+		newBreaker.Enable = *config.Enable
+	}
+	if config.Rollback != nil {
+		newBreaker.Rollback = *config.Rollback
+	}
+	return newBreaker
+}
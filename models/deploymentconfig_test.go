@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DeploymentConfig_ApplyTo_Nil(t *testing.T) {
+	deploymentConfig := &models.DeploymentConfig{MaximumPercent: aws.Int32(150)}
+	newConfig := deploymentConfig.ApplyTo(nil)
+	assert.Equal(t, int32(150), *newConfig.MaximumPercent)
+	assert.Nil(t, newConfig.MinimumHealthyPercent)
+	assert.Nil(t, newConfig.DeploymentCircuitBreaker)
+}
+
+func Test_DeploymentConfig_ApplyTo_KeepsOtherSettings(t *testing.T) {
+	alarms := &types.DeploymentAlarms{AlarmNames: []string{"alarm"}}
+	current := &types.DeploymentConfiguration{
+		Alarms:                   alarms,
+		MaximumPercent:           aws.Int32(200),
+		MinimumHealthyPercent:    aws.Int32(100),
+		DeploymentCircuitBreaker: &types.DeploymentCircuitBreaker{Enable: true, Rollback: false},
+	}
+	deploymentConfig := &models.DeploymentConfig{
+		MinimumHealthyPercent:    aws.Int32(50),
+		DeploymentCircuitBreaker: &models.CircuitBreakerConfig{Rollback: aws.Bool(true)},
+	}
+	newConfig := deploymentConfig.ApplyTo(current)
+	assert.Equal(t, alarms, newConfig.Alarms)
+	assert.Equal(t, int32(200), *newConfig.MaximumPercent)
+	assert.Equal(t, int32(50), *newConfig.MinimumHealthyPercent)
+	assert.True(t, newConfig.DeploymentCircuitBreaker.Enable)
+	assert.True(t, newConfig.DeploymentCircuitBreaker.Rollback)
+	assert.False(t, current.DeploymentCircuitBreaker.Rollback)
+	assert.Equal(t, int32(100), *current.MinimumHealthyPercent)
+}
@@ -0,0 +1,14 @@
+package models
+
+// HooksConfig holds the local commands that run around a deploy. Pre hooks
+// abort the deploy when they fail, post hooks only report it.
+type HooksConfig struct {
+	// PreRegister runs before the new task definition is registered
+	PreRegister string `json:"preRegister" yaml:"preRegister"`
+	// PreUpdate runs right before the service is updated
+	PreUpdate string `json:"preUpdate" yaml:"preUpdate"`
+	// PostSuccess runs once the service reflects the changes
+	PostSuccess string `json:"postSuccess" yaml:"postSuccess"`
+	// PostFailure runs when the deploy fails after it started
+	PostFailure string `json:"postFailure" yaml:"postFailure"`
+}
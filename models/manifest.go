@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes many services to deploy at once
+type Manifest struct {
+	Parallelism int              `json:"parallelism" yaml:"parallelism"`
+	Deployments []*ManifestEntry `json:"deployments" yaml:"deployments"`
+}
+
+// ManifestEntry describes the deploy of a single service
+type ManifestEntry struct {
+	Target                    `yaml:",inline"`
+	Name                      string          `json:"name" yaml:"name"`
+	Cluster                   string          `json:"cluster" yaml:"cluster"`
+	Service                   string          `json:"service" yaml:"service"`
+	UpdatesFile               string          `json:"updatesFile" yaml:"updatesFile"`
+	Updates                   *Updates        `json:"updates" yaml:"updates"`
+	DependsOn                 []string        `json:"dependsOn" yaml:"dependsOn"`
+	CodeDeployApplication     string          `json:"codeDeployApplication" yaml:"codeDeployApplication"`
+	CodeDeployDeploymentGroup string          `json:"codeDeployDeploymentGroup" yaml:"codeDeployDeploymentGroup"`
+	Canary                    *ManifestCanary `json:"canary" yaml:"canary"`
+}
+
+// ManifestCanary is the canary service a manifest entry is tried on first
+type ManifestCanary struct {
+	Service string `json:"service" yaml:"service"`
+	// BakeTime is how long the canary runs before we check it, the --canary-bake-time flag when zero
+	BakeTime        time.Duration `json:"bakeTime" yaml:"bakeTime"`
+	MaxStoppedTasks int           `json:"maxStoppedTasks" yaml:"maxStoppedTasks"`
+	Alarms          []string      `json:"alarms" yaml:"alarms"`
+}
+
+// ParseManifest reads a manifest and checks its entries make sense
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Deployments) == 0 {
+		return nil, errors.New("the manifest has no deployments")
+	}
+	if manifest.Parallelism < 0 {
+		return nil, errors.New("parallelism can't be negative")
+	}
+	names := make(map[string]struct{}, len(manifest.Deployments))
+	for i, entry := range manifest.Deployments {
+		if entry.Cluster == "" || entry.Service == "" {
+			return nil, fmt.Errorf("deployment #%d needs a cluster and a service", i+1)
+		}
+		if entry.Name == "" {
+			entry.Name = entry.Cluster + "/" + entry.Service
+			if entry.Region != "" {
+				entry.Name = entry.Region + "/" + entry.Name
+			}
+		}
+		if entry.Canary != nil && entry.Canary.Service == "" {
+			return nil, fmt.Errorf("the canary of deployment %s needs a service", entry.Name)
+		}
+		if entry.UpdatesFile != "" && entry.Updates != nil {
+			return nil, fmt.Errorf("deployment %s can't have both updates and an updatesFile", entry.Name)
+		}
+		if _, prs := names[entry.Name]; prs {
+			return nil, fmt.Errorf("deployment %s is listed more than once", entry.Name)
+		}
+		names[entry.Name] = struct{}{}
+	}
+	if _, err := manifest.Waves(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Waves groups the deployments so each one comes after the ones it depends on
+func (manifest *Manifest) Waves() ([][]*ManifestEntry, error) {
+	return SortWaves(
+		manifest.Deployments,
+		func(entry *ManifestEntry) string { return entry.Name },
+		func(entry *ManifestEntry) []string { return entry.DependsOn },
+	)
+}
@@ -0,0 +1,175 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseManifest(t *testing.T) {
+	data := []byte(`
+parallelism: 4
+deployments:
+  - cluster: production
+    service: api
+    updatesFile: api.yml
+  - name: workers
+    cluster: production
+    service: worker
+    updates:
+      containerDefinitions:
+        worker:
+          image: "worker:2"
+      service:
+        desiredCount: 3
+`)
+	manifest, err := models.ParseManifest(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, manifest.Parallelism)
+	assert.Len(t, manifest.Deployments, 2)
+	assert.Equal(t, "production/api", manifest.Deployments[0].Name)
+	assert.Equal(t, "api.yml", manifest.Deployments[0].UpdatesFile)
+	assert.Nil(t, manifest.Deployments[0].Updates)
+	assert.Equal(t, "workers", manifest.Deployments[1].Name)
+	assert.Equal(t, "worker:2", *manifest.Deployments[1].Updates.ContainerDefinitions["worker"].Image)
+	assert.Equal(t, int32(3), *manifest.Deployments[1].Updates.Service.DesiredCount)
+}
+
+func Test_ParseManifest_Empty(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`deployments: []`))
+	assert.Error(t, err)
+	assert.Equal(t, "the manifest has no deployments", err.Error())
+}
+
+func Test_ParseManifest_MissingService(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+`))
+	assert.Error(t, err)
+	assert.Equal(t, "deployment #1 needs a cluster and a service", err.Error())
+}
+
+func Test_ParseManifest_UpdatesAndUpdatesFile(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+    service: api
+    updatesFile: api.yml
+    updates:
+      cpu: "256"
+`))
+	assert.Error(t, err)
+	assert.Equal(t, "deployment production/api can't have both updates and an updatesFile", err.Error())
+}
+
+func Test_ParseManifest_Duplicates(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+    service: api
+  - cluster: production
+    service: api
+`))
+	assert.Error(t, err)
+	assert.Equal(t, "deployment production/api is listed more than once", err.Error())
+}
+
+func Test_ParseManifest_DependsOn(t *testing.T) {
+	manifest, err := models.ParseManifest([]byte(`
+deployments:
+  - name: cron
+    cluster: production
+    service: cron
+    dependsOn: [workers]
+  - name: workers
+    cluster: production
+    service: worker
+    dependsOn: [production/api]
+  - cluster: production
+    service: api
+`))
+	assert.Nil(t, err)
+	waves, err := manifest.Waves()
+	assert.Nil(t, err)
+	assert.Len(t, waves, 3)
+	assert.Equal(t, "production/api", waves[0][0].Name)
+	assert.Equal(t, "workers", waves[1][0].Name)
+	assert.Equal(t, "cron", waves[2][0].Name)
+}
+
+func Test_ParseManifest_DependencyCycle(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`
+deployments:
+  - name: api
+    cluster: production
+    service: api
+    dependsOn: [workers]
+  - name: workers
+    cluster: production
+    service: worker
+    dependsOn: [api]
+`))
+	assert.Error(t, err)
+	assert.Equal(t, "there's a dependency cycle: api -> workers -> api", err.Error())
+}
+
+func Test_ParseManifest_Targets(t *testing.T) {
+	manifest, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+    service: api
+    region: us-east-1
+  - cluster: production
+    service: api
+    region: eu-west-1
+    profile: europe
+    roleArn: arn:aws:iam::123:role/deployer
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "us-east-1/production/api", manifest.Deployments[0].Name)
+	assert.Equal(t, "eu-west-1/production/api", manifest.Deployments[1].Name)
+	assert.Equal(t, models.Target{
+		Region:  "eu-west-1",
+		Profile: "europe",
+		RoleArn: "arn:aws:iam::123:role/deployer",
+	}, manifest.Deployments[1].Target)
+}
+
+func Test_ParseManifest_CodeDeployAndCanary(t *testing.T) {
+	manifest, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+    service: api
+    codeDeployApplication: api
+    codeDeployDeploymentGroup: api-production
+    canary:
+      service: api-canary
+      bakeTime: 10m
+      maxStoppedTasks: 1
+      alarms: [api-5xx]
+`))
+	assert.Nil(t, err)
+	entry := manifest.Deployments[0]
+	assert.Equal(t, "api", entry.CodeDeployApplication)
+	assert.Equal(t, "api-production", entry.CodeDeployDeploymentGroup)
+	assert.Equal(t, &models.ManifestCanary{
+		Service:         "api-canary",
+		BakeTime:        10 * time.Minute,
+		MaxStoppedTasks: 1,
+		Alarms:          []string{"api-5xx"},
+	}, entry.Canary)
+}
+
+func Test_ParseManifest_CanaryWithoutService(t *testing.T) {
+	_, err := models.ParseManifest([]byte(`
+deployments:
+  - cluster: production
+    service: api
+    canary:
+      bakeTime: 10m
+`))
+	assert.Equal(t, "the canary of deployment production/api needs a service", err.Error())
+}
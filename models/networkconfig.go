@@ -0,0 +1,73 @@
+package models
+
+import "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+// NetworkConfig represents changes we can make to the network configuration of a service
+type NetworkConfig struct {
+	AwsvpcConfiguration *AwsVpcConfig `json:"awsvpcConfiguration" yaml:"awsvpcConfiguration"`
+}
+
+// AwsVpcConfig represents changes we can make to the awsvpc configuration of a service
+type AwsVpcConfig struct {
+	Subnets        *ListPatch `json:"subnets" yaml:"subnets"`
+	SecurityGroups *ListPatch `json:"securityGroups" yaml:"securityGroups"`
+	AssignPublicIP *string    `json:"assignPublicIp" yaml:"assignPublicIp"`
+}
+
+// ListPatch represents entries to add to and remove from a list
+type ListPatch struct {
+	Add    []string `json:"add" yaml:"add"`
+	Remove []string `json:"remove" yaml:"remove"`
+}
+
+// ApplyTo apply a config to the network configuration of a service
+func (config *NetworkConfig) ApplyTo(input *types.NetworkConfiguration) *types.NetworkConfiguration {
+	newConfig := &types.NetworkConfiguration{}
+	if input != nil {
+		newConfig.AwsvpcConfiguration = input.AwsvpcConfiguration
+	}
+	if config.AwsvpcConfiguration != nil {
+		newConfig.AwsvpcConfiguration = config.AwsvpcConfiguration.ApplyTo(newConfig.AwsvpcConfiguration)
+	}
+	return newConfig
+}
+
+// ApplyTo apply a config to an awsvpc configuration
+func (config *AwsVpcConfig) ApplyTo(input *types.AwsVpcConfiguration) *types.AwsVpcConfiguration {
+	newConfig := &types.AwsVpcConfiguration{}
+	if input != nil {
+		newConfig.Subnets = input.Subnets
+		newConfig.SecurityGroups = input.SecurityGroups
+		newConfig.AssignPublicIp = input.AssignPublicIp
+	}
+	newConfig.Subnets = config.Subnets.ApplyTo(newConfig.Subnets)
+	newConfig.SecurityGroups = config.SecurityGroups.ApplyTo(newConfig.SecurityGroups)
+	if config.AssignPublicIP != nil {
+		newConfig.AssignPublicIp = types.AssignPublicIp(*config.AssignPublicIP)
+	}
+	return newConfig
+}
+
+// ApplyTo apply a patch to a list, keeping the order of the entries that stay
+func (patch *ListPatch) ApplyTo(input []string) []string {
+	if patch == nil {
+		return input
+	}
+	removed := make(map[string]struct{}, len(patch.Remove))
+	for _, entry := range patch.Remove {
+		removed[entry] = struct{}{}
+	}
+	present := make(map[string]struct{}, len(input))
+	newList := make([]string, 0, len(input)+len(patch.Add))
+	for _, entry := range append(append([]string{}, input...), patch.Add...) {
+		if _, prs := removed[entry]; prs {
+			continue
+		}
+		if _, prs := present[entry]; prs {
+			continue
+		}
+		present[entry] = struct{}{}
+		newList = append(newList, entry)
+	}
+	return newList
+}
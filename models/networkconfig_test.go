@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ListPatch_ApplyTo_Nil(t *testing.T) {
+	var patch *models.ListPatch
+	assert.Equal(t, []string{"a"}, patch.ApplyTo([]string{"a"}))
+}
+
+func Test_ListPatch_ApplyTo(t *testing.T) {
+	patch := &models.ListPatch{Add: []string{"c", "a"}, Remove: []string{"b"}}
+	assert.Equal(t, []string{"a", "c"}, patch.ApplyTo([]string{"a", "b"}))
+}
+
+func Test_NetworkConfig_ApplyTo(t *testing.T) {
+	networkConfig := &models.NetworkConfig{
+		AwsvpcConfiguration: &models.AwsVpcConfig{
+			SecurityGroups: &models.ListPatch{Add: []string{"sg-2"}, Remove: []string{"sg-1"}},
+			AssignPublicIP: aws.String("ENABLED"),
+		},
+	}
+	current := &types.NetworkConfiguration{
+		AwsvpcConfiguration: &types.AwsVpcConfiguration{
+			Subnets:        []string{"subnet-1"},
+			SecurityGroups: []string{"sg-1"},
+			AssignPublicIp: types.AssignPublicIpDisabled,
+		},
+	}
+	newConfig := networkConfig.ApplyTo(current)
+	assert.Equal(t, []string{"subnet-1"}, newConfig.AwsvpcConfiguration.Subnets)
+	assert.Equal(t, []string{"sg-2"}, newConfig.AwsvpcConfiguration.SecurityGroups)
+	assert.Equal(t, types.AssignPublicIpEnabled, newConfig.AwsvpcConfiguration.AssignPublicIp)
+	assert.Equal(t, []string{"sg-1"}, current.AwsvpcConfiguration.SecurityGroups)
+}
+
+func Test_NetworkConfig_ApplyTo_Nil(t *testing.T) {
+	networkConfig := &models.NetworkConfig{
+		AwsvpcConfiguration: &models.AwsVpcConfig{Subnets: &models.ListPatch{Add: []string{"subnet-1"}}},
+	}
+	newConfig := networkConfig.ApplyTo(nil)
+	assert.Equal(t, []string{"subnet-1"}, newConfig.AwsvpcConfiguration.Subnets)
+	assert.Empty(t, newConfig.AwsvpcConfiguration.SecurityGroups)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PreDeployTaskConfig describes a one-off task, like a database migration,
+// that runs with the new task definition before the service is updated
+type PreDeployTaskConfig struct {
+	// Container gets the command override, the first container when empty
+	Container string `json:"container" yaml:"container"`
+	// Command overrides the command of the container, the task definition's when empty
+	Command []string `json:"command" yaml:"command"`
+	// Timeout is how long we wait for the task to stop, the deploy timeout when zero
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// DeregisterOnFailure deregisters the new task definition if the task fails
+	DeregisterOnFailure bool `json:"deregisterOnFailure" yaml:"deregisterOnFailure"`
+}
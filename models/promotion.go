@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// NewTaskConfigFromTaskDefinition builds the config that gives other task
+// definitions the images of the given containers, all of them when none are
+// given, along with the values of the given environment variables
+func NewTaskConfigFromTaskDefinition(definition *types.TaskDefinition, containers []string, environment []string) (*TaskConfig, error) {
+	byName := make(map[string]types.ContainerDefinition, len(definition.ContainerDefinitions))
+	for _, container := range definition.ContainerDefinitions {
+		byName[*container.Name] = container
+	}
+	if len(containers) == 0 {
+		for _, container := range definition.ContainerDefinitions {
+			containers = append(containers, *container.Name)
+		}
+	}
+
+	config := &TaskConfig{ContainerDefinitions: make(map[string]ContainerConfig, len(containers))}
+	for _, name := range containers {
+		container, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("the task definition has no container %s", name)
+		}
+		containerConfig := ContainerConfig{}
+		if container.Image != nil {
+			image := *container.Image
+			containerConfig.Image = &image
+		}
+		for _, pair := range container.Environment {
+			for _, variable := range environment {
+				if aws.ToString(pair.Name) != variable {
+					continue
+				}
+				if containerConfig.Environment == nil {
+					containerConfig.Environment = make(map[string]string)
+				}
+				containerConfig.Environment[variable] = aws.ToString(pair.Value)
+			}
+		}
+		config.ContainerDefinitions[name] = containerConfig
+	}
+	return config, nil
+}
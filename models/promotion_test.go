@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var promotedTaskDefinition = &types.TaskDefinition{
+	ContainerDefinitions: []types.ContainerDefinition{
+		{
+			Name:  aws.String("web"),
+			Image: aws.String("web:v2"),
+			Environment: []types.KeyValuePair{
+				{Name: aws.String("VERSION"), Value: aws.String("2")},
+				{Name: aws.String("DATABASE_URL"), Value: aws.String("staging")},
+			},
+		},
+		{
+			Name:  aws.String("sidecar"),
+			Image: aws.String("sidecar:v7"),
+		},
+	},
+}
+
+func Test_NewTaskConfigFromTaskDefinition(t *testing.T) {
+	config, err := models.NewTaskConfigFromTaskDefinition(promotedTaskDefinition, nil, []string{"VERSION"})
+	assert.Nil(t, err)
+	assert.Equal(t, &models.TaskConfig{
+		ContainerDefinitions: map[string]models.ContainerConfig{
+			"web": {
+				Image:       aws.String("web:v2"),
+				Environment: map[string]string{"VERSION": "2"},
+			},
+			"sidecar": {Image: aws.String("sidecar:v7")},
+		},
+	}, config)
+}
+
+func Test_NewTaskConfigFromTaskDefinition_Containers(t *testing.T) {
+	config, err := models.NewTaskConfigFromTaskDefinition(promotedTaskDefinition, []string{"sidecar"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &models.TaskConfig{
+		ContainerDefinitions: map[string]models.ContainerConfig{
+			"sidecar": {Image: aws.String("sidecar:v7")},
+		},
+	}, config)
+
+	_, err = models.NewTaskConfigFromTaskDefinition(promotedTaskDefinition, []string{"worker"}, nil)
+	assert.EqualError(t, err, "the task definition has no container worker")
+}
+
+func Test_NewTaskConfigFromTaskDefinition_NilValue(t *testing.T) {
+	definition := &types.TaskDefinition{
+		ContainerDefinitions: []types.ContainerDefinition{
+			{
+				Name:        aws.String("web"),
+				Image:       aws.String("web:v2"),
+				Environment: []types.KeyValuePair{{Name: aws.String("VERSION")}},
+			},
+		},
+	}
+	config, err := models.NewTaskConfigFromTaskDefinition(definition, nil, []string{"VERSION"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"VERSION": ""}, config.ContainerDefinitions["web"].Environment)
+}
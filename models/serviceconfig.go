@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// ServiceConfig represents changes we can make to services
+type ServiceConfig struct {
+	DesiredCount             *int32                   `json:"desiredCount" yaml:"desiredCount"`
+	DeploymentConfiguration  *DeploymentConfig        `json:"deploymentConfiguration" yaml:"deploymentConfiguration"`
+	CapacityProviderStrategy []CapacityProviderConfig `json:"capacityProviderStrategy" yaml:"capacityProviderStrategy"`
+	NetworkConfiguration     *NetworkConfig           `json:"networkConfiguration" yaml:"networkConfiguration"`
+}
+
+// ApplyTo apply a config to a service, the resulting input only carries the
+// settings this config changes so we don't clobber anything else
+func (config *ServiceConfig) ApplyTo(service *types.Service) (*ecs.UpdateServiceInput, *Change) {
+	was := &ecs.UpdateServiceInput{Cluster: service.ClusterArn, Service: service.ServiceName}
+	newInput := &ecs.UpdateServiceInput{Cluster: service.ClusterArn, Service: service.ServiceName}
+
+	if config.DesiredCount != nil {
+		was.DesiredCount = aws.Int32(service.DesiredCount)
+		newInput.DesiredCount = config.DesiredCount
+	}
+	if config.DeploymentConfiguration != nil {
+		was.DeploymentConfiguration = service.DeploymentConfiguration
+		newInput.DeploymentConfiguration = config.DeploymentConfiguration.ApplyTo(service.DeploymentConfiguration)
+	}
+
+	if config.CapacityProviderStrategy != nil {
+		was.CapacityProviderStrategy = service.CapacityProviderStrategy
+		newInput.CapacityProviderStrategy = capacityProviderStrategy(config.CapacityProviderStrategy)
+	}
+	if config.NetworkConfiguration != nil {
+		was.NetworkConfiguration = service.NetworkConfiguration
+		newInput.NetworkConfiguration = config.NetworkConfiguration.ApplyTo(service.NetworkConfiguration)
+	}
+
+	diff := Compare(was, newInput)
+	diff.Name = "service"
+
+	// ECS only moves tasks to a new capacity provider strategy on a new deployment
+	if !Compare(was.CapacityProviderStrategy, newInput.CapacityProviderStrategy).Empty() {
+		newInput.ForceNewDeployment = true
+	}
+	return newInput, diff
+}
@@ -0,0 +1,146 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServiceConfig_ApplyTo_Empty(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{}
+	service := &types.Service{ClusterArn: aws.String("arn::cluster"), ServiceName: aws.String("service"), DesiredCount: 2}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.Equal(t, "arn::cluster", *input.Cluster)
+	assert.Equal(t, "service", *input.Service)
+	assert.Nil(t, input.DesiredCount)
+	assert.True(t, diff.Empty())
+}
+
+func Test_ServiceConfig_ApplyTo_DesiredCount(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{DesiredCount: aws.Int32(4)}
+	service := &types.Service{ClusterArn: aws.String("arn::cluster"), ServiceName: aws.String("service"), DesiredCount: 2}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.Equal(t, int32(4), *input.DesiredCount)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, "service.desiredCount was: 2 and now is: 4", diff.String())
+}
+
+func Test_ServiceConfig_ApplyTo_SameDesiredCount(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{DesiredCount: aws.Int32(2)}
+	service := &types.Service{ClusterArn: aws.String("arn::cluster"), ServiceName: aws.String("service"), DesiredCount: 2}
+	_, diff := serviceConfig.ApplyTo(service)
+	assert.True(t, diff.Empty())
+}
+
+func Test_ServiceConfig_ApplyTo_DeploymentConfiguration(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{
+		DeploymentConfiguration: &models.DeploymentConfig{
+			MinimumHealthyPercent:    aws.Int32(50),
+			DeploymentCircuitBreaker: &models.CircuitBreakerConfig{Enable: aws.Bool(true), Rollback: aws.Bool(true)},
+		},
+	}
+	service := &types.Service{
+		ClusterArn:  aws.String("arn::cluster"),
+		ServiceName: aws.String("service"),
+		DeploymentConfiguration: &types.DeploymentConfiguration{
+			MaximumPercent:           aws.Int32(200),
+			MinimumHealthyPercent:    aws.Int32(100),
+			DeploymentCircuitBreaker: &types.DeploymentCircuitBreaker{},
+		},
+	}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.Equal(t, int32(200), *input.DeploymentConfiguration.MaximumPercent)
+	assert.Equal(t, int32(50), *input.DeploymentConfiguration.MinimumHealthyPercent)
+	assert.Equal(t, strings.Join([]string{
+		"service.deploymentConfiguration.deploymentCircuitBreaker.enable was: false and now is: true",
+		"service.deploymentConfiguration.deploymentCircuitBreaker.rollback was: false and now is: true",
+		"service.deploymentConfiguration.minimumHealthyPercent was: 100 and now is: 50",
+	}, "\n"), diff.String())
+}
+
+func Test_ServiceConfig_ApplyTo_UnchangedDeploymentConfiguration(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{
+		DeploymentConfiguration: &models.DeploymentConfig{MaximumPercent: aws.Int32(200)},
+	}
+	service := &types.Service{
+		ClusterArn:              aws.String("arn::cluster"),
+		ServiceName:             aws.String("service"),
+		DeploymentConfiguration: &types.DeploymentConfiguration{MaximumPercent: aws.Int32(200)},
+	}
+	_, diff := serviceConfig.ApplyTo(service)
+	assert.True(t, diff.Empty())
+}
+
+func Test_ServiceConfig_ApplyTo_CapacityProviderStrategy(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{
+		CapacityProviderStrategy: []models.CapacityProviderConfig{
+			{CapacityProvider: "FARGATE", Weight: 1, Base: 1},
+			{CapacityProvider: "FARGATE_SPOT", Weight: 3},
+		},
+	}
+	service := &types.Service{
+		ClusterArn:  aws.String("arn::cluster"),
+		ServiceName: aws.String("service"),
+		CapacityProviderStrategy: []types.CapacityProviderStrategyItem{
+			{CapacityProvider: aws.String("FARGATE"), Weight: 1, Base: 1},
+		},
+	}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.Len(t, input.CapacityProviderStrategy, 2)
+	assert.True(t, input.ForceNewDeployment)
+	assert.Equal(
+		t,
+		"service.capacityProviderStrategy[\"FARGATE_SPOT\"] was added: {capacityProvider: \"FARGATE_SPOT\", weight: 3}",
+		diff.String(),
+	)
+}
+
+func Test_ServiceConfig_ApplyTo_UnchangedCapacityProviderStrategy(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{
+		CapacityProviderStrategy: []models.CapacityProviderConfig{{CapacityProvider: "FARGATE", Weight: 1}},
+	}
+	service := &types.Service{
+		ClusterArn:  aws.String("arn::cluster"),
+		ServiceName: aws.String("service"),
+		CapacityProviderStrategy: []types.CapacityProviderStrategyItem{
+			{CapacityProvider: aws.String("FARGATE"), Weight: 1},
+		},
+	}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.False(t, input.ForceNewDeployment)
+	assert.True(t, diff.Empty())
+}
+
+func Test_ServiceConfig_ApplyTo_NetworkConfiguration(t *testing.T) {
+	serviceConfig := &models.ServiceConfig{
+		NetworkConfiguration: &models.NetworkConfig{
+			AwsvpcConfiguration: &models.AwsVpcConfig{
+				Subnets:        &models.ListPatch{Add: []string{"subnet-1"}},
+				SecurityGroups: &models.ListPatch{Add: []string{"sg-3"}, Remove: []string{"sg-1"}},
+			},
+		},
+	}
+	service := &types.Service{
+		ClusterArn:  aws.String("arn::cluster"),
+		ServiceName: aws.String("service"),
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{
+				Subnets:        []string{"subnet-2", "subnet-1"},
+				SecurityGroups: []string{"sg-1", "sg-2"},
+				AssignPublicIp: types.AssignPublicIpDisabled,
+			},
+		},
+	}
+	input, diff := serviceConfig.ApplyTo(service)
+	assert.Equal(t, []string{"subnet-2", "subnet-1"}, input.NetworkConfiguration.AwsvpcConfiguration.Subnets)
+	assert.Equal(t, []string{"sg-2", "sg-3"}, input.NetworkConfiguration.AwsvpcConfiguration.SecurityGroups)
+	assert.Equal(t, types.AssignPublicIpDisabled, input.NetworkConfiguration.AwsvpcConfiguration.AssignPublicIp)
+	assert.Equal(t, strings.Join([]string{
+		"service.networkConfiguration.awsvpcConfiguration.securityGroups was removed: \"sg-1\"",
+		"service.networkConfiguration.awsvpcConfiguration.securityGroups was added: \"sg-3\"",
+	}, "\n"), diff.String())
+}
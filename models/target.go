@@ -0,0 +1,22 @@
+package models
+
+// Target is where a service lives: the region and the credentials to reach it
+type Target struct {
+	Region  string `json:"region" yaml:"region"`
+	Profile string `json:"profile" yaml:"profile"`
+	RoleArn string `json:"roleArn" yaml:"roleArn"`
+}
+
+// Or fills the settings missing in the target with the defaults
+func (target Target) Or(defaults Target) Target {
+	if target.Region == "" {
+		target.Region = defaults.Region
+	}
+	if target.Profile == "" {
+		target.Profile = defaults.Profile
+	}
+	if target.RoleArn == "" {
+		target.RoleArn = defaults.RoleArn
+	}
+	return target
+}
@@ -0,0 +1,19 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Target_Or(t *testing.T) {
+	target := models.Target{Region: "eu-west-1"}
+	defaults := models.Target{Region: "us-east-1", Profile: "production"}
+	assert.Equal(t, models.Target{Region: "eu-west-1", Profile: "production"}, target.Or(defaults))
+}
+
+func Test_Target_Or_Empty(t *testing.T) {
+	target := models.Target{RoleArn: "arn:aws:iam::123:role/deployer"}
+	assert.Equal(t, target, target.Or(models.Target{}))
+}
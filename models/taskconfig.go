@@ -1,6 +1,9 @@
 package models
 
 import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
@@ -12,9 +15,30 @@ type TaskConfig struct {
 	ContainerDefinitions map[string]ContainerConfig `json:"containerDefinitions" yaml:"containerDefinitions"`
 }
 
+// Empty tells whether the config changes nothing
+func (config *TaskConfig) Empty() bool {
+	return config.CPU == nil && config.Memory == nil && len(config.ContainerDefinitions) == 0
+}
+
+// MissingContainers are the containers the config changes that the task
+// definition doesn't have, sorted by name
+func (config *TaskConfig) MissingContainers(input *ecs.RegisterTaskDefinitionInput) []string {
+	existing := make(map[string]bool, len(input.ContainerDefinitions))
+	for _, definition := range input.ContainerDefinitions {
+		existing[aws.ToString(definition.Name)] = true
+	}
+	var missing []string
+	for name := range config.ContainerDefinitions {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
 // ApplyTo apply a config to register task definition input
-func (config *TaskConfig) ApplyTo(input *ecs.RegisterTaskDefinitionInput) (*ecs.RegisterTaskDefinitionInput, *TaskConfigDiff) {
-	diff := &TaskConfigDiff{}
+func (config *TaskConfig) ApplyTo(input *ecs.RegisterTaskDefinitionInput) *ecs.RegisterTaskDefinitionInput {
 	newInput := &ecs.RegisterTaskDefinitionInput{
 		ContainerDefinitions:    input.ContainerDefinitions,
 		Family:                  input.Family,
@@ -34,21 +58,19 @@ func (config *TaskConfig) ApplyTo(input *ecs.RegisterTaskDefinitionInput) (*ecs.
 		TaskRoleArn:             input.TaskRoleArn,
 		Volumes:                 input.Volumes,
 	}
-	updateString(newInput.Cpu, config.CPU, func(val string) { newInput.Cpu = &val }, diff.ChangeCPU)
-	updateString(newInput.Memory, config.Memory, func(val string) { newInput.Memory = &val }, diff.ChangeMemory)
+	updateString(newInput.Cpu, config.CPU, func(val string) { newInput.Cpu = &val })
+	updateString(newInput.Memory, config.Memory, func(val string) { newInput.Memory = &val })
 
 	// Update container definitions
 	newDefs := make([]types.ContainerDefinition, 0, len(newInput.ContainerDefinitions))
 	for _, definition := range newInput.ContainerDefinitions {
 		if config, ok := config.ContainerDefinitions[*definition.Name]; ok {
-			newDef, newDiff := config.ApplyTo(&definition)
-			newDefs = append(newDefs, newDef)
-			diff.ChangeContainer(*definition.Name, newDiff)
+			newDefs = append(newDefs, config.ApplyTo(&definition))
 		} else {
 			newDefs = append(newDefs, definition)
 		}
 	}
 	newInput.ContainerDefinitions = newDefs
 
-	return newInput, diff
+	return newInput
 }
@@ -13,8 +13,30 @@ import (
 func Test_TaskConfig_ApplyTo_Empty(t *testing.T) {
 	taskConfig := &models.TaskConfig{}
 	input := &ecs.RegisterTaskDefinitionInput{}
-	_, diff := taskConfig.ApplyTo(input)
-	assert.True(t, diff.Empty())
+	assert.True(t, models.CompareTaskDefinitions(input, taskConfig.ApplyTo(input)).Empty())
+}
+
+func Test_TaskConfig_Empty(t *testing.T) {
+	assert.True(t, (&models.TaskConfig{}).Empty())
+	assert.False(t, (&models.TaskConfig{Memory: aws.String("512")}).Empty())
+	assert.False(t, (&models.TaskConfig{
+		ContainerDefinitions: map[string]models.ContainerConfig{"web": {}},
+	}).Empty())
+}
+
+func Test_TaskConfig_MissingContainers(t *testing.T) {
+	taskConfig := &models.TaskConfig{
+		ContainerDefinitions: map[string]models.ContainerConfig{
+			"web":     {},
+			"worker":  {},
+			"cleaner": {},
+		},
+	}
+	input := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{Name: aws.String("web")}},
+	}
+	assert.Equal(t, []string{"cleaner", "worker"}, taskConfig.MissingContainers(input))
+	assert.Nil(t, (&models.TaskConfig{}).MissingContainers(input))
 }
 
 func Test_TaskConfig_ApplyTo_CPU(t *testing.T) {
@@ -22,9 +44,7 @@ func Test_TaskConfig_ApplyTo_CPU(t *testing.T) {
 		CPU: aws.String("256"),
 	}
 	input := &ecs.RegisterTaskDefinitionInput{}
-	newInput, diff := taskConfig.ApplyTo(input)
-	assert.False(t, diff.Empty())
-	assert.Equal(t, "CPU was: <nil> and now is: \"256\"", diff.String())
+	newInput := taskConfig.ApplyTo(input)
 	assert.NotNil(t, newInput.Cpu)
 	assert.Equal(t, "256", *newInput.Cpu)
 }
@@ -34,9 +54,7 @@ func Test_TaskConfig_ApplyTo_Memory(t *testing.T) {
 		Memory: aws.String("512"),
 	}
 	input := &ecs.RegisterTaskDefinitionInput{}
-	newInput, diff := taskConfig.ApplyTo(input)
-	assert.False(t, diff.Empty())
-	assert.Equal(t, "memory was: <nil> and now is: \"512\"", diff.String())
+	newInput := taskConfig.ApplyTo(input)
 	assert.NotNil(t, newInput.Memory)
 	assert.Equal(t, "512", *newInput.Memory)
 }
@@ -50,8 +68,7 @@ func Test_TaskConfig_ApplyTo_ContainerDefinitions_NonExistent(t *testing.T) {
 		},
 	}
 	input := &ecs.RegisterTaskDefinitionInput{}
-	_, diff := taskConfig.ApplyTo(input)
-	assert.True(t, diff.Empty())
+	assert.True(t, models.CompareTaskDefinitions(input, taskConfig.ApplyTo(input)).Empty())
 }
 
 func Test_TaskConfig_ApplyTo_ContainerDefinitions_Existent(t *testing.T) {
@@ -69,9 +86,7 @@ func Test_TaskConfig_ApplyTo_ContainerDefinitions_Existent(t *testing.T) {
 			},
 		},
 	}
-	newInput, diff := taskConfig.ApplyTo(input)
-	assert.False(t, diff.Empty())
-	assert.Equal(t, "the container definition \"container\" changed in this way:\nCPU was: 0 and now is: 256\n", diff.String())
+	newInput := taskConfig.ApplyTo(input)
 	assert.Equal(t, 1, len(newInput.ContainerDefinitions))
 	assert.Equal(t, int32(256), newInput.ContainerDefinitions[0].Cpu)
 }
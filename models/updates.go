@@ -0,0 +1,9 @@
+package models
+
+// Updates represents everything that can be changed through an updates file
+type Updates struct {
+	TaskConfig    `yaml:",inline"`
+	Service       ServiceConfig        `json:"service" yaml:"service"`
+	PreDeployTask *PreDeployTaskConfig `json:"preDeployTask" yaml:"preDeployTask"`
+	Hooks         HooksConfig          `json:"hooks" yaml:"hooks"`
+}
@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Updates_Unmarshal(t *testing.T) {
+	data := []byte(`
+cpu: "256"
+containerDefinitions:
+  web:
+    image: "web:2"
+service:
+  desiredCount: 3
+preDeployTask:
+  container: web
+  command: ["./manage.py", "migrate"]
+  timeout: 10m
+`)
+	var updates models.Updates
+	assert.Nil(t, yaml.Unmarshal(data, &updates))
+	assert.Equal(t, "256", *updates.CPU)
+	assert.Equal(t, "web:2", *updates.ContainerDefinitions["web"].Image)
+	assert.Equal(t, int32(3), *updates.Service.DesiredCount)
+	assert.Equal(t, &models.PreDeployTaskConfig{
+		Container: "web",
+		Command:   []string{"./manage.py", "migrate"},
+		Timeout:   10 * time.Minute,
+	}, updates.PreDeployTask)
+}
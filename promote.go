@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+)
+
+func promoteCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "promote",
+		Usage:     "Deploy the images a service runs to another service",
+		UsageText: "ecs-deploy promote [options] --from <cluster>/<service> --to <cluster>/<service>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "from",
+				Usage:    "Promote the images of this `CLUSTER/SERVICE`",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    "Deploy the images to this `CLUSTER/SERVICE`",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:        "containers",
+				Usage:       "Only promote the images of these `CONTAINERS`, separated by commas",
+				DefaultText: "all of them",
+				Required:    false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "env",
+				Usage:    "Promote the values of these environment `VARIABLES` too, separated by commas",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:        "from-region",
+				Usage:       "Reach the source service in this AWS `REGION`",
+				DefaultText: "--region",
+				Required:    false,
+			},
+			&cli.StringFlag{
+				Name:        "from-profile",
+				Usage:       "Reach the source service with this AWS `PROFILE`",
+				DefaultText: "--profile",
+				Required:    false,
+			},
+			&cli.StringFlag{
+				Name:        "from-role-arn",
+				Usage:       "Reach the source service assuming the role with this `ARN`",
+				DefaultText: "--role-arn",
+				Required:    false,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 0 || cmd.String("from") == "" || cmd.String("to") == "" {
+				return usageError(cmd, "Please specify the services with --from and --to")
+			}
+			fromCluster, fromService, err := splitServicePath(cmd.String("from"))
+			if err != nil {
+				return usageError(cmd, err.Error())
+			}
+			toCluster, toService, err := splitServicePath(cmd.String("to"))
+			if err != nil {
+				return usageError(cmd, err.Error())
+			}
+
+			target := flagsTarget(cmd)
+			sourceConfig, err := newAWSConfig(ctx, models.Target{
+				Region:  cmd.String("from-region"),
+				Profile: cmd.String("from-profile"),
+				RoleArn: cmd.String("from-role-arn"),
+			}.Or(target))
+			if err != nil {
+				return err
+			}
+			deployer, err := newDeployer(ctx, cmd, target)
+			if err != nil {
+				return err
+			}
+
+			svc := services.NewPromoterService(clients.NewECSClient(ecs.NewFromConfig(sourceConfig)), deployer)
+			return exitError(svc.Promote(ctx, &services.PromoteInput{
+				FromCluster: fromCluster,
+				FromService: fromService,
+				Containers:  cmd.StringSlice("containers"),
+				Environment: cmd.StringSlice("env"),
+				Deploy: &services.DeployInput{
+					Cluster:                   toCluster,
+					Service:                   toService,
+					DryRun:                    cmd.Bool("dry"),
+					Timeout:                   cmd.Duration("timeout"),
+					NoWait:                    cmd.Bool("no-wait"),
+					RollbackOnFailure:         cmd.Bool("rollback-on-failure"),
+					RollbackOnInterrupt:       cmd.Bool("rollback-on-interrupt"),
+					UpdateScheduledTasks:      cmd.Bool("update-scheduled-tasks"),
+					LockOwner:                 cmd.String("lock-owner"),
+					BreakLock:                 cmd.Bool("break-lock"),
+					Prune:                     flagsPrune(cmd),
+					Canary:                    flagsCanary(cmd),
+					Confirm:                   flagsConfirm(cmd, false),
+					CodeDeployApplication:     cmd.String("codedeploy-application"),
+					CodeDeployDeploymentGroup: cmd.String("codedeploy-deployment-group"),
+				},
+			}))
+		},
+	}
+}
+
+// splitServicePath splits <cluster>/<service>, the cluster can be an ARN
+func splitServicePath(path string) (string, string, error) {
+	separator := strings.LastIndex(path, "/")
+	if separator <= 0 || separator == len(path)-1 {
+		return "", "", fmt.Errorf("%q is not a <cluster>/<service>", path)
+	}
+	return path[:separator], path[separator+1:], nil
+}
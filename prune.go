@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+)
+
+func pruneCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "prune",
+		Usage:     "Deregister old revisions of a service's task definition family",
+		ArgsUsage: "<cluster> <service>",
+		UsageText: "ecs-deploy prune [options] <cluster> <service>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 2 {
+				return usageError(cmd, "Please specify a cluster and service to prune")
+			}
+			if cmd.Int("keep") < 0 {
+				return usageError(cmd, "Please keep zero or more revisions")
+			}
+			args := cmd.Args()
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			return svc.Prune(ctx, &services.PruneInput{
+				Cluster:   args.Get(0),
+				Service:   args.Get(1),
+				Retention: flagsRetention(cmd),
+				DryRun:    cmd.Bool("dry"),
+			})
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func registerCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "register",
+		Usage:     "Register a new revision of a task definition no service uses and print its ARN",
+		ArgsUsage: "<family|arn>",
+		UsageText: "ecs-deploy register [options] <family|arn>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:     "json",
+				Usage:    "Print the ARN, family and revision of the new task definition as JSON",
+				Required: false,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 1 {
+				return usageError(cmd, "Please specify a task definition family or ARN to register")
+			}
+
+			data, err := readConfigPayload(cmd.String("updates"))
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read input file"), exitCodeInput)
+			}
+
+			var updates models.Updates
+			if err := yaml.Unmarshal(data, &updates); err != nil {
+				return err
+			}
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			output, err := svc.Register(ctx, &services.RegisterInput{
+				TaskDefinition: cmd.Args().Get(0),
+				NewConfig:      updates.TaskConfig,
+				DryRun:         cmd.Bool("dry"),
+			})
+			if err != nil || output == nil {
+				return err
+			}
+
+			// the progress goes to stderr, so stdout only has what downstream tools need
+			if !cmd.Bool("json") {
+				fmt.Println(output.TaskDefinitionArn)
+				return nil
+			}
+			encoded, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(encoded))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+)
+
+func restartCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "restart",
+		Usage:     "Start a new deployment of a service without changing it (e.g. to pick up rotated secrets)",
+		ArgsUsage: "<cluster> <service>",
+		UsageText: "ecs-deploy restart [options] <cluster> <service>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 2 {
+				return usageError(cmd, "Please specify a cluster and service to restart")
+			}
+			args := cmd.Args()
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			return exitError(svc.Deploy(ctx, &services.DeployInput{
+				Cluster:                   args.Get(0),
+				Service:                   args.Get(1),
+				DryRun:                    cmd.Bool("dry"),
+				Timeout:                   cmd.Duration("timeout"),
+				NoWait:                    cmd.Bool("no-wait"),
+				ForceNewDeployment:        true,
+				RollbackOnFailure:         cmd.Bool("rollback-on-failure"),
+				RollbackOnInterrupt:       cmd.Bool("rollback-on-interrupt"),
+				Confirm:                   flagsConfirm(cmd, false),
+				LockOwner:                 cmd.String("lock-owner"),
+				BreakLock:                 cmd.Bool("break-lock"),
+				CodeDeployApplication:     cmd.String("codedeploy-application"),
+				CodeDeployDeploymentGroup: cmd.String("codedeploy-deployment-group"),
+			}))
+		},
+	}
+}
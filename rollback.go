@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+)
+
+func rollbackCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rollback",
+		Usage:     "Point a service back to a previous revision of its task definition",
+		ArgsUsage: "<cluster> <service>",
+		UsageText: "ecs-deploy rollback [options] <cluster> <service>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "to",
+				Usage:    "Roll back to this `REVISION` of the task definition family",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "steps",
+				Usage:    "Roll back `N` ACTIVE revisions from the current one",
+				Value:    1,
+				Required: false,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 2 {
+				return usageError(cmd, "Please specify a cluster and service to roll back")
+			}
+			if cmd.IsSet("to") && cmd.IsSet("steps") {
+				return usageError(cmd, "Please specify either --to or --steps, not both")
+			}
+			if cmd.Bool("break-lock") && cmd.String("lock") == "" {
+				return usageError(cmd, "Please specify the lock store to break the lock in with --lock")
+			}
+			args := cmd.Args()
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			return svc.Rollback(ctx, &services.RollbackInput{
+				Cluster:                   args.Get(0),
+				Service:                   args.Get(1),
+				Revision:                  int32(cmd.Int("to")),
+				Steps:                     int(cmd.Int("steps")),
+				DryRun:                    cmd.Bool("dry"),
+				Timeout:                   cmd.Duration("timeout"),
+				NoWait:                    cmd.Bool("no-wait"),
+				Confirm:                   flagsConfirm(cmd, false),
+				LockOwner:                 cmd.String("lock-owner"),
+				BreakLock:                 cmd.Bool("break-lock"),
+				CodeDeployApplication:     cmd.String("codedeploy-application"),
+				CodeDeployDeploymentGroup: cmd.String("codedeploy-deployment-group"),
+			})
+		},
+	}
+}
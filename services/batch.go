@@ -0,0 +1,246 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/fatih/color"
+)
+
+// BatchDeployment is a single deploy out of a batch
+type BatchDeployment struct {
+	// Name identifies the deploy in the logs and in the summary
+	Name string
+	// Input is the input for the deploy, its logger gets replaced to prefix every line with the name
+	Input *DeployInput
+	// DependsOn are the names of the deploys that must succeed before this one starts
+	DependsOn []string
+	// Deployer runs this deploy, nil means the batch's deployer (e.g. set it for services in other regions or accounts)
+	Deployer DeployerService
+}
+
+// BatchDeployInput represents the input for the BatchDeployerService
+type BatchDeployInput struct {
+	// Deployments are the deploys to run
+	Deployments []*BatchDeployment
+	// Parallelism is how many deploys can run at the same time
+	Parallelism int
+	// Output is where the deploys report their progress, nil means the standard logger's output
+	Output io.Writer
+}
+
+// BatchDeployerService is the interface for deploying many services at once
+type BatchDeployerService interface {
+	// DeployAll will run every deploy and print a summary of the results
+	DeployAll(ctx context.Context, input *BatchDeployInput) error
+}
+
+type batchDeployerService struct {
+	deployer DeployerService
+}
+
+// NewBatchDeployerService creates a new BatchDeployerService
+func NewBatchDeployerService(deployer DeployerService) BatchDeployerService {
+	return &batchDeployerService{deployer: deployer}
+}
+
+type batchResult struct {
+	name     string
+	err      error
+	skipped  bool
+	duration time.Duration
+}
+
+func (s *batchDeployerService) DeployAll(ctx context.Context, input *BatchDeployInput) error {
+	output := input.Output
+	if output == nil {
+		output = log.Writer()
+	}
+	parallelism := input.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	waves, err := models.SortWaves(
+		input.Deployments,
+		func(deployment *BatchDeployment) string { return deployment.Name },
+		func(deployment *BatchDeployment) []string { return deployment.DependsOn },
+	)
+	if err != nil {
+		return err
+	}
+	dependedOn := make(map[string]struct{})
+	for _, deployment := range input.Deployments {
+		for _, dependency := range deployment.DependsOn {
+			dependedOn[dependency] = struct{}{}
+		}
+	}
+
+	var outputLock sync.Mutex
+	results := make(map[string]*batchResult, len(input.Deployments))
+	slots := make(chan struct{}, parallelism)
+	for _, wave := range waves {
+		var wg sync.WaitGroup
+		for _, deployment := range wave {
+			result := &batchResult{name: deployment.Name}
+			results[deployment.Name] = result
+			if failed := failedDependency(deployment, results); failed != "" {
+				result.skipped = true
+				result.err = fmt.Errorf("skipped because %s didn't succeed", failed)
+				continue
+			}
+
+			deployInput := *deployment.Input
+			writer := &prefixWriter{
+				lock:   &outputLock,
+				out:    output,
+				prefix: fmt.Sprintf("[%s] ", deployment.Name),
+			}
+			deployInput.Logger = log.New(writer, "", 0)
+			if _, prs := dependedOn[deployment.Name]; prs && deployInput.NoWait {
+				// the deploys that depend on this one need it to reach steady state first
+				deployInput.NoWait = false
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				deployer := deployment.Deployer
+				if deployer == nil {
+					deployer = s.deployer
+				}
+				start := time.Now()
+				result.err = deployer.Deploy(ctx, &deployInput)
+				result.duration = time.Since(start)
+				// hooks may leave their last line unfinished
+				if err := writer.Flush(); err != nil && result.err == nil {
+					result.err = err
+				}
+				if result.err != nil {
+					deployInput.Logger.Println(color.RedString("%s", result.err))
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	ordered := make([]*batchResult, 0, len(input.Deployments))
+	for _, deployment := range input.Deployments {
+		ordered = append(ordered, results[deployment.Name])
+	}
+	return summarize(output, ordered)
+}
+
+// failedDependency finds a dependency of the deploy that failed or was skipped
+func failedDependency(deployment *BatchDeployment, results map[string]*batchResult) string {
+	for _, dependency := range deployment.DependsOn {
+		if results[dependency].err != nil {
+			return dependency
+		}
+	}
+	return ""
+}
+
+// summarize prints a table with the result of every deploy
+func summarize(output io.Writer, results []*batchResult) error {
+	failed, skipped := 0, 0
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\nSERVICE\tRESULT\tDURATION\tERROR")
+	for _, result := range results {
+		status := "ok"
+		reason := ""
+		if result.skipped {
+			skipped++
+			status = "skipped"
+			reason = result.err.Error()
+		} else if result.err != nil {
+			failed++
+			status = "failed"
+			reason = strings.SplitN(result.err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", result.name, status, result.duration.Round(time.Second), reason)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	if skipped > 0 {
+		return fmt.Errorf("%d of %d deployments failed and %d were skipped", failed, len(results), skipped)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deployments failed", failed, len(results))
+	}
+	return nil
+}
+
+// prefixWriter prefixes every line written to it, it shares a lock with the
+// other writers on the same output so lines don't get mixed up. Lines are only
+// written out once they're complete, so writes of a bit of a line (like the
+// output of hooks) don't get split up.
+type prefixWriter struct {
+	lock    *sync.Mutex
+	out     io.Writer
+	prefix  string
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.partial = append(w.partial, p...)
+	end := bytes.LastIndexByte(w.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	lines := w.partial[:end+1]
+	w.partial = append([]byte(nil), w.partial[end+1:]...)
+	if err := w.writeLines(lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out the unfinished line, if there's one
+func (w *prefixWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := append(w.partial, '\n')
+	w.partial = nil
+	return w.writeLines(line)
+}
+
+// writeLines writes the prefixed lines out, the caller holds the lock
+func (w *prefixWriter) writeLines(lines []byte) error {
+	var prefixed bytes.Buffer
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		prefixed.WriteString(w.prefix)
+		prefixed.Write(line)
+	}
+	_, err := w.out.Write(prefixed.Bytes())
+	return err
+}
+
+// progress is where the waits print their dots: the output of the logger,
+// except in batches, where every write becomes a line of its own
+func progress(logger *log.Logger) io.Writer {
+	if _, batched := logger.Writer().(*prefixWriter); batched {
+		return io.Discard
+	}
+	return logger.Writer()
+}
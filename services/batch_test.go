@@ -0,0 +1,288 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/services"
+	mock_services "github.com/adroll/ecs-ship/services/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func batchDeployment(name string) *services.BatchDeployment {
+	parts := strings.Split(name, "/")
+	return &services.BatchDeployment{
+		Name:  name,
+		Input: &services.DeployInput{Cluster: parts[0], Service: parts[1]},
+	}
+}
+
+func Test_BatchDeployer_DeployAll_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	var output bytes.Buffer
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{batchDeployment("cluster/api"), batchDeployment("cluster/worker")},
+		Parallelism: 2,
+		Output:      &output,
+	}
+
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *services.DeployInput) error {
+		input.Logger.Println("deploying\nin two lines")
+		return nil
+	}).Times(2)
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "[cluster/api] deploying\n[cluster/api] in two lines\n")
+	assert.Contains(t, output.String(), "[cluster/worker] deploying\n[cluster/worker] in two lines\n")
+	assert.Regexp(t, `cluster/api\s+ok`, output.String())
+	assert.Regexp(t, `cluster/worker\s+ok`, output.String())
+	assert.Nil(t, input.Deployments[0].Input.Logger)
+}
+
+func Test_BatchDeployer_DeployAll_Failure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	var output bytes.Buffer
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{batchDeployment("cluster/api"), batchDeployment("cluster/worker")},
+		Parallelism: 1,
+		Output:      &output,
+	}
+
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *services.DeployInput) error {
+		if input.Service == "worker" {
+			return assert.AnError
+		}
+		return nil
+	}).Times(2)
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 2 deployments failed", err.Error())
+	assert.Regexp(t, `cluster/api\s+ok`, output.String())
+	assert.Regexp(t, `cluster/worker\s+failed\s+\S+\s+assert.AnError general error for testing`, output.String())
+}
+
+func Test_BatchDeployer_DeployAll_Parallelism(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{
+			batchDeployment("cluster/a"),
+			batchDeployment("cluster/b"),
+			batchDeployment("cluster/c"),
+			batchDeployment("cluster/d"),
+		},
+		Parallelism: 2,
+		Output:      &bytes.Buffer{},
+	}
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(context.Context, *services.DeployInput) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		<-release
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}).Times(4)
+
+	go func() {
+		for i := 0; i < 4; i++ {
+			release <- struct{}{}
+		}
+	}()
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, maxRunning, 2)
+}
+
+func Test_BatchDeployer_DeployAll_DependencyOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	api := batchDeployment("cluster/api")
+	api.Input.NoWait = true
+	worker := batchDeployment("cluster/worker")
+	worker.DependsOn = []string{"cluster/api"}
+	worker.Input.NoWait = true
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{worker, api},
+		Parallelism: 2,
+		Output:      &bytes.Buffer{},
+	}
+
+	gomock.InOrder(
+		mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *services.DeployInput) error {
+			assert.Equal(t, "api", input.Service)
+			assert.False(t, input.NoWait)
+			return nil
+		}),
+		mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *services.DeployInput) error {
+			assert.Equal(t, "worker", input.Service)
+			assert.True(t, input.NoWait)
+			return nil
+		}),
+	)
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_BatchDeployer_DeployAll_SkipsDependentsOfFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	var output bytes.Buffer
+	worker := batchDeployment("cluster/worker")
+	worker.DependsOn = []string{"cluster/api"}
+	cron := batchDeployment("cluster/cron")
+	cron.DependsOn = []string{"cluster/worker"}
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{batchDeployment("cluster/api"), worker, cron, batchDeployment("cluster/web")},
+		Parallelism: 2,
+		Output:      &output,
+	}
+
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *services.DeployInput) error {
+		if input.Service == "api" {
+			return assert.AnError
+		}
+		return nil
+	}).Times(2)
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 4 deployments failed and 2 were skipped", err.Error())
+	assert.Regexp(t, `cluster/worker\s+skipped\s+\S+\s+skipped because cluster/api didn't succeed`, output.String())
+	assert.Regexp(t, `cluster/cron\s+skipped\s+\S+\s+skipped because cluster/worker didn't succeed`, output.String())
+	assert.Regexp(t, `cluster/web\s+ok`, output.String())
+}
+
+func Test_BatchDeployer_DeployAll_Cycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	api := batchDeployment("cluster/api")
+	api.DependsOn = []string{"cluster/api"}
+	input := &services.BatchDeployInput{Deployments: []*services.BatchDeployment{api}}
+
+	err := batchDeployer.DeployAll(context.Background(), input)
+	assert.Error(t, err)
+	assert.Equal(t, "there's a dependency cycle: cluster/api -> cluster/api", err.Error())
+}
+
+func Test_BatchDeployer_DeployAll_DeployerPerDeployment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	otherRegionDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	europe := batchDeployment("cluster/api")
+	europe.Name = "eu-west-1/cluster/api"
+	europe.Deployer = otherRegionDeployer
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{batchDeployment("cluster/api"), europe},
+		Parallelism: 2,
+		Output:      &bytes.Buffer{},
+	}
+
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).Return(nil)
+	otherRegionDeployer.EXPECT().Deploy(ctx, gomock.Any()).Return(nil)
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_BatchDeployer_DeployAll_NoProgressDots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	batchDeployer := services.NewBatchDeployerService(services.NewDeployerService(mockClient))
+	ctx := context.Background()
+	var output bytes.Buffer
+	deployment := batchDeployment("cluster/api")
+	deployment.Input.ForceNewDeployment = true
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{deployment},
+		Parallelism: 1,
+		Output:      &output,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("api"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::api:1"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, "cluster", "api").Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateService(ctx, gomock.Any()).Return(service, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, service, time.Duration(0), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *types.Service, _ time.Duration, progress io.Writer) error {
+			fmt.Fprint(progress, "...")
+			fmt.Fprintln(progress)
+			return nil
+		})
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "[cluster/api] service has been updated successfully!")
+	assert.NotContains(t, output.String(), ".\n")
+}
+
+func Test_BatchDeployer_DeployAll_PrefixesWholeLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	batchDeployer := services.NewBatchDeployerService(mockDeployer)
+	ctx := context.Background()
+	var output bytes.Buffer
+	input := &services.BatchDeployInput{
+		Deployments: []*services.BatchDeployment{batchDeployment("cluster/api")},
+		Parallelism: 1,
+		Output:      &output,
+	}
+
+	mockDeployer.EXPECT().Deploy(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, deployInput *services.DeployInput) error {
+			// the way a hook writes its output
+			writer := deployInput.Logger.Writer()
+			fmt.Fprint(writer, "migrat")
+			fmt.Fprint(writer, "ing\n10 rows")
+			fmt.Fprint(writer, " updated")
+			return nil
+		})
+
+	err := batchDeployer.DeployAll(ctx, input)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(output.String(), "[cluster/api] migrating\n[cluster/api] 10 rows updated\n"))
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// CanaryInput describes the canary service of a deploy and the gates it has
+// to pass before the main service gets updated
+type CanaryInput struct {
+	// Service is the name of the canary service, in the same cluster as the main one
+	Service string
+	// BakeTime is how long the canary runs the new task definition before we check the gates
+	BakeTime time.Duration
+	// MaxStoppedTasks is how many tasks the canary may stop while baking
+	MaxStoppedTasks int
+	// Alarms are CloudWatch alarms that must not be firing once the canary baked
+	Alarms []string
+}
+
+// deployCanary points the canary service to the new task definition, lets
+// it bake and checks the gates, rolling the canary back if any of them fails
+func (s *deployerService) deployCanary(ctx context.Context, logger *log.Logger, input *DeployInput, taskDefinition *types.TaskDefinition) error {
+	canary := input.Canary
+	logger.Printf("deploying to the canary service %s first\n", canary.Service)
+	canaryService, err := s.client.GetService(ctx, input.Cluster, canary.Service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get canary service")
+	}
+
+	startedAt := time.Now()
+	updatedCanary, err := s.client.UpdateTaskDefinition(ctx, canaryService, taskDefinition)
+	if err != nil {
+		return errorx.Decorate(err, "unable to update canary service")
+	}
+
+	logger.Println("waiting for the canary to reflect the changes")
+	if err := s.client.WaitForServiceToLookGood(ctx, updatedCanary, input.Timeout, progress(logger)); err != nil {
+		if ctx.Err() != nil {
+			return s.interrupted(ctx, logger, input, canaryService, *taskDefinition.TaskDefinitionArn)
+		}
+		return s.rollback(ctx, logger, canaryService, input.Timeout, errorx.Decorate(err, "canary did not reflect changes"))
+	}
+
+	if canary.BakeTime > 0 {
+		logger.Printf("letting the canary bake for %s\n", canary.BakeTime)
+		select {
+		case <-time.After(canary.BakeTime):
+		case <-ctx.Done():
+			return s.interrupted(ctx, logger, input, canaryService, *taskDefinition.TaskDefinitionArn)
+		}
+	}
+
+	if err := s.checkCanary(ctx, canary, updatedCanary, *taskDefinition.TaskDefinitionArn, startedAt); err != nil {
+		return s.rollback(ctx, logger, canaryService, input.Timeout, err)
+	}
+
+	logger.Println(color.GreenString("the canary looks good, moving on to %s", input.Service))
+	return nil
+}
+
+// checkCanary evaluates the gates of the canary once it baked
+func (s *deployerService) checkCanary(ctx context.Context, canary *CanaryInput, service *types.Service, taskDefinition string, since time.Time) error {
+	refreshedService, err := s.client.GetService(ctx, *service.ClusterArn, *service.ServiceName)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get canary service")
+	}
+	looksGood, err := s.client.DoesServiceLookGood(ctx, refreshedService)
+	if err != nil {
+		return errorx.Decorate(err, "unable to check if the canary looks good")
+	}
+	if !looksGood {
+		return errors.New("the canary doesn't look good after baking")
+	}
+
+	stopped, err := s.client.CountStoppedTasks(ctx, refreshedService, taskDefinition, since)
+	if err != nil {
+		return errorx.Decorate(err, "unable to count the stopped tasks of the canary")
+	}
+	if stopped > canary.MaxStoppedTasks {
+		return fmt.Errorf("the canary stopped %d tasks, but only %d are allowed", stopped, canary.MaxStoppedTasks)
+	}
+
+	if len(canary.Alarms) == 0 {
+		return nil
+	}
+	if s.alarms == nil {
+		return errors.New("unable to check the canary alarms without an alarm client")
+	}
+	firing, err := s.alarms.AlarmsInAlarm(ctx, canary.Alarms)
+	if err != nil {
+		return errorx.Decorate(err, "unable to check the canary alarms")
+	}
+	if len(firing) > 0 {
+		return fmt.Errorf("these alarms went off during the canary: %s", strings.Join(firing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_CanaryPasses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockAlarms := mock_clients.NewMockAlarmClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithAlarmClient(mockAlarms))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Canary: &services.CanaryInput{
+			Service:         "service-canary",
+			MaxStoppedTasks: 1,
+			Alarms:          []string{"errors"},
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	canaryService := &types.Service{
+		ServiceName:    aws.String("service-canary"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newCanaryService := &types.Service{
+		ServiceName: aws.String("service-canary"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().GetService(ctx, "cluster", "service-canary").Return(canaryService, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, canaryService, newTaskDefinition).Return(newCanaryService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newCanaryService, input.Timeout, gomock.Any()).Return(nil)
+	mockClient.EXPECT().GetService(ctx, "arn::cluster", "service-canary").Return(newCanaryService, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, newCanaryService).Return(true, nil)
+
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().CountStoppedTasks(ctx, newCanaryService, "arn::task:2", gomock.Any()).Return(1, nil)
+	mockAlarms.EXPECT().AlarmsInAlarm(ctx, []string{"errors"}).Return(nil, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_CanaryStopsTooManyTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Canary: &services.CanaryInput{
+			Service:         "service-canary",
+			MaxStoppedTasks: 1,
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	canaryService := &types.Service{
+		ServiceName:    aws.String("service-canary"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newCanaryService := &types.Service{
+		ServiceName: aws.String("service-canary"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().GetService(ctx, "cluster", "service-canary").Return(canaryService, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, canaryService, newTaskDefinition).Return(newCanaryService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newCanaryService, input.Timeout, gomock.Any()).Return(nil)
+	mockClient.EXPECT().GetService(ctx, "arn::cluster", "service-canary").Return(newCanaryService, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, newCanaryService).Return(true, nil)
+
+	rolledBackCanary := &types.Service{
+		ServiceName: aws.String("service-canary"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().CountStoppedTasks(ctx, newCanaryService, "arn::task:2", gomock.Any()).Return(2, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, canaryService, &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:1")}).Return(rolledBackCanary, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, rolledBackCanary, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	var canaryErr *services.CanaryError
+	assert.ErrorAs(t, err, &canaryErr)
+	assert.Equal(t, "service-canary", canaryErr.Canary)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Nil(t, rollbackErr.RollbackCause)
+	assert.Equal(t, strings.Join([]string{
+		"canary failed, service was not updated, cause: the canary stopped 2 tasks, but only 1 are allowed",
+		"the service was rolled back to arn::task:1",
+	}, "\n"), err.Error())
+}
+
+func Test_Deployer_CanaryAlarmsGoOff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockAlarms := mock_clients.NewMockAlarmClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithAlarmClient(mockAlarms))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Canary: &services.CanaryInput{
+			Service: "service-canary",
+			Alarms:  []string{"errors", "latency"},
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	canaryService := &types.Service{
+		ServiceName:    aws.String("service-canary"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newCanaryService := &types.Service{
+		ServiceName: aws.String("service-canary"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().GetService(ctx, "cluster", "service-canary").Return(canaryService, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, canaryService, newTaskDefinition).Return(newCanaryService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newCanaryService, input.Timeout, gomock.Any()).Return(nil)
+	mockClient.EXPECT().GetService(ctx, "arn::cluster", "service-canary").Return(newCanaryService, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, newCanaryService).Return(true, nil)
+
+	rolledBackCanary := &types.Service{
+		ServiceName: aws.String("service-canary"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().CountStoppedTasks(ctx, newCanaryService, "arn::task:2", gomock.Any()).Return(0, nil)
+	mockAlarms.EXPECT().AlarmsInAlarm(ctx, []string{"errors", "latency"}).Return([]string{"latency"}, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, canaryService, &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:1")}).Return(rolledBackCanary, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, rolledBackCanary, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Equal(t, strings.Join([]string{
+		"canary failed, service was not updated, cause: these alarms went off during the canary: latency",
+		"the service was rolled back to arn::task:1",
+	}, "\n"), err.Error())
+}
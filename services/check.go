@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// CheckInput represents the input for checking a service against its updates
+type CheckInput struct {
+	// Cluster is the name of the ECS cluster
+	Cluster string
+	// Service is the name of the ECS service
+	Service string
+	// NewConfig is the configuration the task definition should have
+	NewConfig models.TaskConfig
+	// ServiceConfig is the configuration the service settings should have
+	ServiceConfig models.ServiceConfig
+}
+
+func (s *deployerService) Check(ctx context.Context, input *CheckInput) (*models.Change, error) {
+	log.Printf("checking service:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
+	service, err := s.client.GetService(ctx, input.Cluster, input.Service)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to get service")
+	}
+
+	output, err := s.client.GetTaskDefinition(ctx, service)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to get task definition")
+	}
+
+	oldTaskDefinitionInput := s.client.CopiedTaskDefinition(output)
+	missing := input.NewConfig.MissingContainers(oldTaskDefinitionInput)
+	newTaskDefinitionInput := input.NewConfig.ApplyTo(oldTaskDefinitionInput)
+	diff := models.CompareTaskDefinitions(oldTaskDefinitionInput, newTaskDefinitionInput)
+	_, serviceDiff := input.ServiceConfig.ApplyTo(service)
+
+	if len(missing) == 0 && diff.Empty() && serviceDiff.Empty() {
+		log.Println(color.GreenString("the service matches the updates"))
+		return mergeChanges(), nil
+	}
+
+	if len(missing) > 0 {
+		log.Println(color.YellowString("the task definition has no containers named %s", strings.Join(missing, ", ")))
+	}
+	if !diff.Empty() || !serviceDiff.Empty() {
+		log.Println(color.YellowString("the service has drifted, these are the changes a deploy would make:"))
+	}
+	for _, changes := range []*models.Change{diff, serviceDiff} {
+		if !changes.Empty() {
+			log.Println(changes)
+		}
+	}
+	return mergeChanges(missingContainers(input.NewConfig, missing), diff, serviceDiff), nil
+}
+
+// missingContainers reports the containers of the updates the task definition
+// doesn't have as added, a deploy would ignore them so they are drift too
+func missingContainers(config models.TaskConfig, missing []string) *models.Change {
+	change := &models.Change{Name: "containerDefinitions"}
+	for _, name := range missing {
+		change.Children = append(change.Children, &models.Change{
+			Name:  fmt.Sprintf("[%q]", name),
+			Kind:  models.Added,
+			IsNow: config.ContainerDefinitions[name],
+		})
+	}
+	return change
+}
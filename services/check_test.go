@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.CheckInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		NewConfig: models.TaskConfig{CPU: aws.String("256")},
+	}
+	service := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 2,
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+
+	drift, err := deployer.Check(ctx, input)
+	assert.Nil(t, err)
+	assert.True(t, drift.Empty())
+	encoded, err := json.Marshal(drift)
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", string(encoded))
+}
+
+func Test_Deployer_CheckDrifted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.CheckInput{
+		Cluster:       "cluster",
+		Service:       "service",
+		NewConfig:     models.TaskConfig{CPU: aws.String("512")},
+		ServiceConfig: models.ServiceConfig{DesiredCount: aws.Int32(3)},
+	}
+	service := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 2,
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+
+	drift, err := deployer.Check(ctx, input)
+	assert.Nil(t, err)
+	assert.False(t, drift.Empty())
+	encoded, err := json.Marshal(drift)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"path": "cpu", "kind": "modified", "was": "256", "isNow": "512"},
+		{"path": "service.desiredCount", "kind": "modified", "was": 2, "isNow": 3}
+	]`, string(encoded))
+}
+
+func Test_Deployer_CheckMissingContainer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.CheckInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			ContainerDefinitions: map[string]models.ContainerConfig{
+				"worker": {Image: aws.String("worker:v2")},
+			},
+		},
+	}
+	service := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 2,
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{Name: aws.String("web"), Image: aws.String("web:v1")}},
+	})
+
+	drift, err := deployer.Check(ctx, input)
+	assert.Nil(t, err)
+	assert.False(t, drift.Empty())
+	assert.Equal(t, `containerDefinitions["worker"] was added: {image: "worker:v2"}`, drift.String())
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// usesCodeDeploy tells if the service is deployed through CodeDeploy, which
+// doesn't let us update its task definition directly
+func usesCodeDeploy(service *types.Service) bool {
+	return service.DeploymentController != nil && service.DeploymentController.Type == types.DeploymentControllerTypeCodeDeploy
+}
+
+// checkCodeDeploy tells why we can't deploy the service if it uses the
+// CODE_DEPLOY controller, nil if we can or it doesn't use it
+func (s *deployerService) checkCodeDeploy(service *types.Service) error {
+	if !usesCodeDeploy(service) {
+		return nil
+	}
+	if s.codeDeploy == nil {
+		return errors.New("the service is deployed with CodeDeploy, but there's no CodeDeploy client")
+	}
+	// the AppSpec only has room for the container of one load balancer
+	if len(service.LoadBalancers) > 1 {
+		return fmt.Errorf("the service is deployed with CodeDeploy and has %d load balancers, but CodeDeploy only supports one", len(service.LoadBalancers))
+	}
+	return nil
+}
+
+// codeDeployNames are the CodeDeploy application and deployment group of the
+// service, defaulting to the names the ECS console gives them
+func codeDeployNames(cluster string, application string, deploymentGroup string, service *types.Service) (string, string) {
+	cluster = cluster[strings.LastIndex(cluster, "/")+1:]
+	if application == "" {
+		application = fmt.Sprintf("AppECS-%s-%s", cluster, aws.ToString(service.ServiceName))
+	}
+	if deploymentGroup == "" {
+		deploymentGroup = fmt.Sprintf("DgpECS-%s-%s", cluster, aws.ToString(service.ServiceName))
+	}
+	return application, deploymentGroup
+}
+
+// deployWithCodeDeploy creates a blue/green deployment of the task definition
+// and waits for CodeDeploy to finish it
+func (s *deployerService) deployWithCodeDeploy(ctx context.Context, logger *log.Logger, input *DeployInput, service *types.Service, serviceInput *ecs.UpdateServiceInput, serviceDiff *models.Change, newTaskDefinition *types.TaskDefinition) error {
+	taskDefinition := aws.ToString(service.TaskDefinition)
+	if newTaskDefinition != nil {
+		taskDefinition = *newTaskDefinition.TaskDefinitionArn
+	}
+	// the updates carry the settings they mention even when they end up the same
+	needsDeployment := newTaskDefinition != nil || input.ForceNewDeployment ||
+		serviceDiff.Changed("networkConfiguration") || serviceDiff.Changed("capacityProviderStrategy")
+	appSpec := models.NewAppSpec(service, taskDefinition, serviceInput)
+
+	if serviceDiff.Changed("desiredCount") || serviceDiff.Changed("deploymentConfiguration") {
+		// the replacement task set gets the rest from the AppSpec, the
+		// service only takes these
+		update := &ecs.UpdateServiceInput{
+			Cluster:                 serviceInput.Cluster,
+			Service:                 serviceInput.Service,
+			DesiredCount:            serviceInput.DesiredCount,
+			DeploymentConfiguration: serviceInput.DeploymentConfiguration,
+		}
+		if _, err := s.client.UpdateService(ctx, update); err != nil {
+			return errorx.Decorate(err, "unable to update service")
+		}
+	}
+	if !needsDeployment {
+		logger.Println(color.GreenString("service has been updated successfully!"))
+		return nil
+	}
+
+	content, err := appSpec.Content()
+	if err != nil {
+		return errorx.Decorate(err, "unable to build the AppSpec")
+	}
+	application, deploymentGroup := codeDeployNames(input.Cluster, input.CodeDeployApplication, input.CodeDeployDeploymentGroup, service)
+	logger.Printf("creating a CodeDeploy deployment:\n  application: %s\n  deployment group: %s\n", application, deploymentGroup)
+	deploymentID, err := s.codeDeploy.CreateDeployment(ctx, application, deploymentGroup, content)
+	if err != nil {
+		return errorx.Decorate(err, "unable to create CodeDeploy deployment")
+	}
+
+	if input.NoWait {
+		logger.Printf("not waiting for the deployment %s to finish, check the console instead.\n", deploymentID)
+		return nil
+	}
+
+	logger.Printf("waiting for the deployment %s to finish\n", deploymentID)
+	if err := s.codeDeploy.WaitForDeployment(ctx, deploymentID, input.Timeout, progress(logger)); err != nil {
+		if ctx.Err() != nil {
+			return s.interruptedCodeDeploy(ctx, logger, input, service, deploymentID, taskDefinition)
+		}
+		if input.RollbackOnFailure {
+			logger.Println(color.YellowString("CodeDeploy rolls blue/green deployments back by itself, check the auto rollback settings of %s", deploymentGroup))
+		}
+		return errorx.Decorate(err, "CodeDeploy deployment did not succeed")
+	}
+
+	logger.Println(color.GreenString("service has been updated successfully!"))
+	return nil
+}
@@ -0,0 +1,245 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func codeDeployService() *types.Service {
+	return &types.Service{
+		ServiceName:          aws.String("service"),
+		ClusterArn:           aws.String("arn::cluster"),
+		TaskDefinition:       aws.String("arn::task:1"),
+		DeploymentController: &types.DeploymentController{Type: types.DeploymentControllerTypeCodeDeploy},
+	}
+}
+
+func Test_Deployer_CodeDeploy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "arn:aws:ecs:us-east-1:123:cluster/cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+	}
+
+	service := codeDeployService()
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockCodeDeploy.EXPECT().CreateDeployment(ctx, "AppECS-cluster-service", "DgpECS-cluster-service", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, appSpec string) (string, error) {
+			assert.True(t, strings.Contains(appSpec, `"TaskDefinition":"arn::task:2"`))
+			return "d-123", nil
+		})
+	mockCodeDeploy.EXPECT().WaitForDeployment(ctx, "d-123", input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_CodeDeployOnlyWhenTheNetworkChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		ServiceConfig: models.ServiceConfig{
+			DesiredCount: aws.Int32(3),
+			// the service already runs in these subnets
+			NetworkConfiguration: &models.NetworkConfig{
+				AwsvpcConfiguration: &models.AwsVpcConfig{
+					Subnets: &models.ListPatch{Add: []string{"subnet-1"}},
+				},
+			},
+		},
+	}
+
+	service := codeDeployService()
+	service.DesiredCount = 2
+	service.NetworkConfiguration = &types.NetworkConfiguration{
+		AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-1"}},
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateService(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, serviceInput *ecs.UpdateServiceInput) (*types.Service, error) {
+			assert.Equal(t, aws.Int32(3), serviceInput.DesiredCount)
+			assert.Nil(t, serviceInput.NetworkConfiguration)
+			return service, nil
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_CodeDeployFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:                   "cluster",
+		Service:                   "service",
+		ForceNewDeployment:        true,
+		CodeDeployApplication:     "app",
+		CodeDeployDeploymentGroup: "group",
+	}
+
+	service := codeDeployService()
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockCodeDeploy.EXPECT().CreateDeployment(ctx, "app", "group", gomock.Any()).Return("d-123", nil)
+	mockCodeDeploy.EXPECT().WaitForDeployment(ctx, "d-123", input.Timeout, gomock.Any()).Return(assert.AnError)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "CodeDeploy deployment did not succeed, cause: assert.AnError general error for testing", err.Error())
+}
+
+func Test_Deployer_CodeDeployWithoutClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+	}
+
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(codeDeployService(), nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "the service is deployed with CodeDeploy, but there's no CodeDeploy client", err.Error())
+}
+
+func Test_Deployer_CodeDeployRollbackOnInterrupt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &services.DeployInput{
+		Cluster:                   "cluster",
+		Service:                   "service",
+		ForceNewDeployment:        true,
+		RollbackOnInterrupt:       true,
+		CodeDeployApplication:     "app",
+		CodeDeployDeploymentGroup: "group",
+	}
+
+	service := codeDeployService()
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockCodeDeploy.EXPECT().CreateDeployment(ctx, "app", "group", gomock.Any()).Return("d-123", nil)
+	mockCodeDeploy.EXPECT().WaitForDeployment(ctx, "d-123", input.Timeout, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ time.Duration, _ io.Writer) error {
+			cancel()
+			return ctx.Err()
+		})
+	mockCodeDeploy.EXPECT().StopDeployment(gomock.Any(), "d-123").Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Nil(t, rollbackErr.RollbackCause)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Deployer_CodeDeployUpdatesOnlyWhatTheServiceTakes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		ServiceConfig: models.ServiceConfig{
+			DesiredCount: aws.Int32(3),
+			CapacityProviderStrategy: []models.CapacityProviderConfig{
+				{CapacityProvider: "FARGATE_SPOT", Weight: 1},
+			},
+		},
+	}
+
+	service := codeDeployService()
+	service.DesiredCount = 2
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateService(ctx, &ecs.UpdateServiceInput{
+		Cluster:      aws.String("arn::cluster"),
+		Service:      aws.String("service"),
+		DesiredCount: aws.Int32(3),
+	}).Return(service, nil)
+	mockCodeDeploy.EXPECT().CreateDeployment(ctx, "AppECS-cluster-service", "DgpECS-cluster-service", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, appSpec string) (string, error) {
+			assert.Contains(t, appSpec, `"CapacityProvider":"FARGATE_SPOT"`)
+			return "d-123", nil
+		})
+	mockCodeDeploy.EXPECT().WaitForDeployment(ctx, "d-123", input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_CodeDeployWithManyLoadBalancers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+	}
+
+	service := codeDeployService()
+	service.LoadBalancers = []types.LoadBalancer{
+		{ContainerName: aws.String("web"), ContainerPort: aws.Int32(8080)},
+		{ContainerName: aws.String("admin"), ContainerPort: aws.Int32(9090)},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "the service is deployed with CodeDeploy and has 2 load balancers, but CodeDeploy only supports one", err.Error())
+}
@@ -1,13 +1,20 @@
 package services
 
+//go:generate mockgen -destination=mocks/deployer.go . DeployerService
+
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/adroll/ecs-ship/clients"
 	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
 )
@@ -20,42 +27,168 @@ type DeployInput struct {
 	Service string
 	// NewConfig is the new configuration for the service
 	NewConfig models.TaskConfig
+	// TaskDefinition is an already registered revision to deploy instead of registering NewConfig, empty means we register one
+	TaskDefinition string
+	// RequireContainers fails the deploy when NewConfig changes containers the task definition doesn't have, instead of ignoring them
+	RequireContainers bool
+	// ServiceConfig is the new configuration for the service settings
+	ServiceConfig models.ServiceConfig
 	// DryRun will only show what would change in the remote service
 	DryRun bool
 	// Timeout is the time to wait for the service to be correctly updated
 	Timeout time.Duration
 	// NoWait will disable waiting for updates to be completed
 	NoWait bool
+	// ForceNewDeployment will start a new deployment even if nothing changed
+	ForceNewDeployment bool
+	// RollbackOnFailure will point the service back to its previous task definition if the deploy fails
+	RollbackOnFailure bool
+	// RollbackOnInterrupt will point the service back to its previous task definition if the context is cancelled while waiting
+	RollbackOnInterrupt bool
+	// UpdateScheduledTasks points the EventBridge rules and schedules running the old task definition to the new one
+	UpdateScheduledTasks bool
+	// Prune cleans up the old revisions of the family once the deploy succeeded, nil means no pruning
+	Prune *Retention
+	// Confirm asks whether to go on once the changes are shown, nil means don't ask
+	Confirm ConfirmFunc
+	// LockOwner identifies this deploy in the lock of the service
+	LockOwner string
+	// BreakLock drops the lock of the service before taking it, for deploys that died holding it
+	BreakLock bool
+	// Hooks are local commands that run around the deploy
+	Hooks models.HooksConfig
+	// PreDeployTask runs with the new task definition before the service is updated, nil means no task
+	PreDeployTask *models.PreDeployTaskConfig
+	// CodeDeployApplication is the CodeDeploy application of services using the CODE_DEPLOY controller, empty means AppECS-<cluster>-<service>
+	CodeDeployApplication string
+	// CodeDeployDeploymentGroup is the CodeDeploy deployment group of the service, empty means DgpECS-<cluster>-<service>
+	CodeDeployDeploymentGroup string
+	// Canary is a service that gets the new task definition before the main one, nil means no canary
+	Canary *CanaryInput
+	// Logger is where we report the progress of the deploy, nil means the standard logger
+	Logger *log.Logger
+}
+
+func (input *DeployInput) logger() *log.Logger {
+	if input.Logger != nil {
+		return input.Logger
+	}
+	return log.Default()
 }
 
 // DeployerService is the interface for the deployer service
 type DeployerService interface {
 	// Deploy will deploy the new configuration to the service
 	Deploy(ctx context.Context, input *DeployInput) error
+	// Rollback will point the service to a previous revision of its task definition
+	Rollback(ctx context.Context, input *RollbackInput) error
+	// Prune will deregister the old revisions of the service's task definition family
+	Prune(ctx context.Context, input *PruneInput) error
+	// Wait will wait for the service to reach a steady state without changing it
+	Wait(ctx context.Context, input *WaitInput) error
+	// Register will register a new revision of a task definition without updating any service
+	Register(ctx context.Context, input *RegisterInput) (*RegisterOutput, error)
+	// Check will tell what a deploy of the updates would change in the service, without changing it
+	Check(ctx context.Context, input *CheckInput) (*models.Change, error)
 }
 
 type deployerService struct {
-	client clients.ECSClient
+	client         clients.ECSClient
+	alarms         clients.AlarmClient
+	codeDeploy     clients.CodeDeployClient
+	hooks          HookRunner
+	locks          clients.LockStore
+	scheduledTasks clients.ScheduledTaskClient
+}
+
+// DeployerOption sets up the optional parts of a DeployerService
+type DeployerOption func(*deployerService)
+
+// WithAlarmClient lets canary deploys check CloudWatch alarms
+func WithAlarmClient(alarms clients.AlarmClient) DeployerOption {
+	return func(s *deployerService) {
+		s.alarms = alarms
+	}
+}
+
+// WithHookRunner changes how the deploy hooks are run
+func WithHookRunner(hooks HookRunner) DeployerOption {
+	return func(s *deployerService) {
+		s.hooks = hooks
+	}
+}
+
+// WithLockStore makes deploys of the same service wait for each other by
+// taking a lock before reading the task definition
+func WithLockStore(locks clients.LockStore) DeployerOption {
+	return func(s *deployerService) {
+		s.locks = locks
+	}
+}
+
+// WithCodeDeployClient lets the deployer update services using the CODE_DEPLOY
+// deployment controller
+func WithCodeDeployClient(codeDeploy clients.CodeDeployClient) DeployerOption {
+	return func(s *deployerService) {
+		s.codeDeploy = codeDeploy
+	}
+}
+
+// WithScheduledTaskClient lets the deployer update the EventBridge rules and
+// schedules running the task definition of the service
+func WithScheduledTaskClient(scheduledTasks clients.ScheduledTaskClient) DeployerOption {
+	return func(s *deployerService) {
+		s.scheduledTasks = scheduledTasks
+	}
 }
 
 // NewDeployerService creates a new DeployerService
-func NewDeployerService(client clients.ECSClient) DeployerService {
-	return &deployerService{client: client}
+func NewDeployerService(client clients.ECSClient, options ...DeployerOption) DeployerService {
+	s := &deployerService{client: client, hooks: NewShellHookRunner()}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
 func (s *deployerService) Deploy(ctx context.Context, input *DeployInput) error {
-	log.Printf("updating service:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
+	if input.Prune != nil {
+		if err := input.Prune.validate(); err != nil {
+			return err
+		}
+	}
+	logger := input.logger()
+	logger.Printf("updating service:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
 	service, err := s.client.GetService(ctx, input.Cluster, input.Service)
 	if err != nil {
 		return errorx.Decorate(err, "unable to get service")
 	}
+	if err := s.checkCodeDeploy(service); err != nil {
+		return err
+	}
+	if input.UpdateScheduledTasks && s.scheduledTasks == nil {
+		return errors.New("we were asked to update the scheduled tasks, but there's no scheduled task client")
+	}
+
+	if s.locks != nil && !input.DryRun {
+		unlock, err := s.lock(ctx, logger, input.Cluster, input.Service, service, input.LockOwner, input.BreakLock)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		// another deploy could have changed the service while we waited for the lock
+		service, err = s.client.GetService(ctx, input.Cluster, input.Service)
+		if err != nil {
+			return errorx.Decorate(err, "unable to get service")
+		}
+	}
 
 	looksGood, err := s.client.DoesServiceLookGood(ctx, service)
 	if err != nil {
 		return errorx.Decorate(err, "unable to check if service looks good")
 	}
 	if looksGood {
-		log.Println(color.GreenString("the service looks good to begin with"))
+		logger.Println(color.GreenString("the service looks good to begin with"))
 	}
 
 	output, err := s.client.GetTaskDefinition(ctx, service)
@@ -65,49 +198,223 @@ func (s *deployerService) Deploy(ctx context.Context, input *DeployInput) error
 
 	oldTaskDefinitionInput := s.client.CopiedTaskDefinition(output)
 
-	newTaskDefinitionInput, diff := input.NewConfig.ApplyTo(oldTaskDefinitionInput)
+	var existingTaskDefinition *types.TaskDefinition
+	var newTaskDefinitionInput *ecs.RegisterTaskDefinitionInput
+	if input.TaskDefinition != "" {
+		target, err := s.client.DescribeTaskDefinition(ctx, input.TaskDefinition)
+		if err != nil {
+			return errorx.Decorate(err, "unable to get target task definition")
+		}
+		existingTaskDefinition = target.TaskDefinition
+		newTaskDefinitionInput = s.client.CopiedTaskDefinition(target)
+	} else {
+		if missing := input.NewConfig.MissingContainers(oldTaskDefinitionInput); input.RequireContainers && len(missing) > 0 {
+			return fmt.Errorf("the task definition of %s has no containers named %s", input.Service, strings.Join(missing, ", "))
+		}
+		newTaskDefinitionInput = input.NewConfig.ApplyTo(oldTaskDefinitionInput)
+	}
+	diff := models.CompareTaskDefinitions(oldTaskDefinitionInput, newTaskDefinitionInput)
+	serviceInput, serviceDiff := input.ServiceConfig.ApplyTo(service)
+	switchingRevision := existingTaskDefinition != nil &&
+		aws.ToString(existingTaskDefinition.TaskDefinitionArn) != aws.ToString(service.TaskDefinition)
 
-	if diff.Empty() {
+	if diff.Empty() && serviceDiff.Empty() && !input.ForceNewDeployment && !switchingRevision {
 		if looksGood {
-			log.Println(color.GreenString("the service is up to date, we have nothing to do :d"))
+			logger.Println(color.GreenString("the service is up to date, we have nothing to do :d"))
 			return nil
 		} else {
 			return errors.New("service does not look good, but no changes were made")
 		}
 	}
 
-	log.Println("these are the changes:")
-	log.Println(diff)
+	if diff.Empty() && serviceDiff.Empty() && switchingRevision {
+		logger.Printf("%s has the same configuration as the current revision, but we'll point the service to it\n", aws.ToString(existingTaskDefinition.TaskDefinitionArn))
+	} else if diff.Empty() && serviceDiff.Empty() {
+		logger.Println("there are no changes, but we'll force a new deployment")
+	} else {
+		logger.Println("these are the changes:")
+		for _, changes := range []*models.Change{diff, serviceDiff} {
+			if !changes.Empty() {
+				logger.Println(changes)
+			}
+		}
+	}
 
 	if input.DryRun {
-		log.Println("not proceeding with the updates because this is a dry run :)")
+		logger.Println("not proceeding with the updates because this is a dry run :)")
 		return nil
 	}
 
-	newTaskDefinition, err := s.client.RegisterTaskDefinition(ctx, newTaskDefinitionInput)
+	if input.Confirm != nil {
+		proceed, err := input.Confirm(fmt.Sprintf("go ahead and update %s?", input.Service))
+		if err != nil {
+			return errorx.Decorate(err, "unable to confirm deploy")
+		}
+		if !proceed {
+			logger.Println("not proceeding with the deploy")
+			return nil
+		}
+	}
+
+	hooks := &hookEnv{
+		cluster:           input.Cluster,
+		service:           input.Service,
+		oldTaskDefinition: aws.ToString(service.TaskDefinition),
+		diff:              mergeChanges(diff, serviceDiff),
+	}
+	err = s.rollout(ctx, logger, input, &rolloutPlan{
+		service:                service,
+		newTaskDefinitionInput: newTaskDefinitionInput,
+		existingTaskDefinition: existingTaskDefinition,
+		serviceInput:           serviceInput,
+		diff:                   diff,
+		serviceDiff:            serviceDiff,
+		hooks:                  hooks,
+	})
 	if err != nil {
-		return errorx.Decorate(err, "unable to register new task definition")
+		s.runPostHook(ctx, logger, "postFailure", input.Hooks.PostFailure, hooks, err)
+		return err
+	}
+	s.runPostHook(ctx, logger, "postSuccess", input.Hooks.PostSuccess, hooks, nil)
+
+	if input.UpdateScheduledTasks && hooks.newTaskDefinition != "" {
+		err := s.updateScheduledTasks(ctx, logger, aws.ToString(service.ClusterArn), hooks.oldTaskDefinition, hooks.newTaskDefinition)
+		if err != nil {
+			logger.Println(color.YellowString("the deploy succeeded, but updating the scheduled tasks failed: %s", err))
+		}
+	}
+
+	if input.Prune != nil {
+		if err := s.prune(ctx, logger, input.Cluster, aws.ToString(output.TaskDefinition.Family), *input.Prune, false); err != nil {
+			logger.Println(color.YellowString("the deploy succeeded, but pruning old revisions failed: %s", err))
+		}
+	}
+	return nil
+}
+
+// rolloutPlan is what Deploy found out it has to change
+type rolloutPlan struct {
+	service                *types.Service
+	newTaskDefinitionInput *ecs.RegisterTaskDefinitionInput
+	existingTaskDefinition *types.TaskDefinition
+	serviceInput           *ecs.UpdateServiceInput
+	diff                   *models.Change
+	serviceDiff            *models.Change
+	hooks                  *hookEnv
+}
+
+// rollout registers the new task definition and updates the service with it
+func (s *deployerService) rollout(ctx context.Context, logger *log.Logger, input *DeployInput, plan *rolloutPlan) error {
+	service := plan.service
+	serviceInput := plan.serviceInput
+	if err := s.runPreHook(ctx, logger, input, "preRegister", input.Hooks.PreRegister, plan.hooks); err != nil {
+		return err
+	}
+
+	newTaskDefinition := plan.existingTaskDefinition
+	var err error
+	if newTaskDefinition != nil {
+		logger.Printf("deploying the already registered %s\n", aws.ToString(newTaskDefinition.TaskDefinitionArn))
+		plan.hooks.newTaskDefinition = aws.ToString(newTaskDefinition.TaskDefinitionArn)
+	} else if !plan.diff.Empty() {
+		newTaskDefinition, err = s.client.RegisterTaskDefinition(ctx, plan.newTaskDefinitionInput)
+		if err != nil {
+			return errorx.Decorate(err, "unable to register new task definition")
+		}
+		plan.hooks.newTaskDefinition = aws.ToString(newTaskDefinition.TaskDefinitionArn)
 	}
 
-	newService, err := s.client.UpdateTaskDefinition(ctx, service, newTaskDefinition)
+	if input.PreDeployTask != nil {
+		if newTaskDefinition == nil {
+			logger.Println("there's no new task definition for the pre-deploy task to run, skipping it")
+		} else if err := s.runPreDeployTask(ctx, logger, input, service, serviceInput, newTaskDefinition); err != nil {
+			// we only clean up revisions we registered ourselves
+			if plan.existingTaskDefinition == nil {
+				err = s.abortAfterPreDeployTask(ctx, logger, input, newTaskDefinition, err)
+			}
+			return errorx.Decorate(err, "aborting the deploy, %s was not updated", input.Service)
+		}
+	}
+
+	if input.Canary != nil {
+		if newTaskDefinition == nil {
+			logger.Println("there's no new task definition for the canary to try, skipping it")
+		} else if err := s.deployCanary(ctx, logger, input, newTaskDefinition); err != nil {
+			return &CanaryError{Canary: input.Canary.Service, Cause: errorx.Decorate(err, "canary failed, %s was not updated", input.Service)}
+		}
+	}
+
+	if err := s.runPreHook(ctx, logger, input, "preUpdate", input.Hooks.PreUpdate, plan.hooks); err != nil {
+		return err
+	}
+
+	if usesCodeDeploy(service) {
+		return s.deployWithCodeDeploy(ctx, logger, input, service, serviceInput, plan.serviceDiff, newTaskDefinition)
+	}
+
+	var newService *types.Service
+	if plan.serviceDiff.Empty() && !input.ForceNewDeployment {
+		newService, err = s.client.UpdateTaskDefinition(ctx, service, newTaskDefinition)
+	} else {
+		if newTaskDefinition != nil {
+			serviceInput.TaskDefinition = newTaskDefinition.TaskDefinitionArn
+		}
+		serviceInput.ForceNewDeployment = serviceInput.ForceNewDeployment || input.ForceNewDeployment
+		newService, err = s.client.UpdateService(ctx, serviceInput)
+	}
 	if err != nil {
 		return errorx.Decorate(err, "unable to update service")
 	}
 
 	if input.NoWait {
-		log.Println("not waiting for your service to reflect the cahnges, check the console instead.")
+		logger.Println("not waiting for your service to reflect the cahnges, check the console instead.")
 		return nil
 	}
 
-	log.Println("waiting for the service to reflect the changes")
+	logger.Println("waiting for the service to reflect the changes")
 
-	err = s.client.WaitForServiceToLookGood(ctx, newService, input.Timeout)
+	err = s.client.WaitForServiceToLookGood(ctx, newService, input.Timeout, progress(logger))
 
+	if err != nil && ctx.Err() != nil {
+		revision := aws.ToString(newService.TaskDefinition)
+		if revision == "" {
+			revision = aws.ToString(service.TaskDefinition)
+		}
+		return s.interrupted(ctx, logger, input, service, revision)
+	}
 	if err != nil {
-		return errorx.Decorate(err, "service did not reflect changes")
+		err = errorx.Decorate(err, "service did not reflect changes")
+		if input.RollbackOnFailure {
+			return s.rollback(ctx, logger, service, input.Timeout, err)
+		}
+		return err
 	}
 
-	log.Println(color.GreenString("service has been updated successfully!"))
+	logger.Println(color.GreenString("service has been updated successfully!"))
 
 	return nil
 }
+
+// rollback points the service back to the task definition it had before the
+// deploy and waits for it to look good again
+func (s *deployerService) rollback(ctx context.Context, logger *log.Logger, service *types.Service, timeout time.Duration, cause error) error {
+	logger.Println(color.RedString("%s", cause))
+	logger.Printf("rolling back to %s\n", *service.TaskDefinition)
+	rollbackErr := &RollbackError{Cause: cause, TaskDefinition: *service.TaskDefinition}
+
+	previousTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: service.TaskDefinition}
+	rolledBackService, err := s.client.UpdateTaskDefinition(ctx, service, previousTaskDefinition)
+	if err != nil {
+		rollbackErr.RollbackCause = errorx.Decorate(err, "unable to update service")
+		return rollbackErr
+	}
+
+	logger.Println("waiting for the service to reflect the rollback")
+	if err := s.client.WaitForServiceToLookGood(ctx, rolledBackService, timeout, progress(logger)); err != nil {
+		rollbackErr.RollbackCause = errorx.Decorate(err, "service did not reflect the rollback")
+		return rollbackErr
+	}
+
+	logger.Println(color.YellowString("service has been rolled back successfully"))
+	return rollbackErr
+}
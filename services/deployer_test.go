@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
@@ -116,6 +117,39 @@ func Test_Deployer_EmptyChanges(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func Test_Deployer_RequireContainers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			ContainerDefinitions: map[string]models.ContainerConfig{
+				"web":    {Image: aws.String("web:v2")},
+				"worker": {Image: aws.String("worker:v2")},
+			},
+		},
+		RequireContainers: true,
+	}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions: []types.ContainerDefinition{{Name: aws.String("web"), Image: aws.String("web:v1")}},
+	})
+
+	err := deployer.Deploy(ctx, input)
+	assert.EqualError(t, err, "the task definition of service has no containers named worker")
+}
+
 func Test_Deployer_DoesntLookGoodButChangesAreEmpty(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockClient := mock_clients.NewMockECSClient(ctrl)
@@ -280,7 +314,7 @@ func Test_Deployer_ServiceDoesntReflectTheChanges(t *testing.T) {
 	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
 	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
 	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefiniton).Return(newService, nil)
-	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout).Return(assert.AnError)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(assert.AnError)
 
 	err := deployer.Deploy(context.Background(), input)
 	assert.Error(t, err)
@@ -319,8 +353,402 @@ func Test_Deployer_ServiceReflectsChanges(t *testing.T) {
 	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
 	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
 	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefiniton).Return(newService, nil)
-	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout).Return(nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_OnlyServiceChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		NewConfig: models.TaskConfig{},
+		ServiceConfig: models.ServiceConfig{
+			DesiredCount: aws.Int32(3),
+		},
+		DryRun:  false,
+		Timeout: 0,
+		NoWait:  false,
+	}
+
+	service := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 1,
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newService := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 3,
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateService(ctx, &ecs.UpdateServiceInput{
+		Cluster:      service.ClusterArn,
+		Service:      service.ServiceName,
+		DesiredCount: aws.Int32(3),
+	}).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_TaskDefinitionAndServiceChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			Memory: aws.String("2048"),
+		},
+		ServiceConfig: models.ServiceConfig{
+			DesiredCount: aws.Int32(3),
+		},
+		DryRun:  false,
+		Timeout: 0,
+		NoWait:  false,
+	}
+
+	service := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 1,
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newTaskDefiniton := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName:  aws.String("service"),
+		ClusterArn:   aws.String("arn::cluster"),
+		DesiredCount: 3,
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
+	mockClient.EXPECT().UpdateService(ctx, &ecs.UpdateServiceInput{
+		Cluster:        service.ClusterArn,
+		Service:        service.ServiceName,
+		DesiredCount:   aws.Int32(3),
+		TaskDefinition: aws.String("arn::task:2"),
+	}).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_ForceNewDeploymentWithoutChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:            "cluster",
+		Service:            "service",
+		NewConfig:          models.TaskConfig{},
+		DryRun:             false,
+		Timeout:            0,
+		NoWait:             false,
+		ForceNewDeployment: true,
+	}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(false, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateService(ctx, &ecs.UpdateServiceInput{
+		Cluster:            service.ClusterArn,
+		Service:            service.ServiceName,
+		ForceNewDeployment: true,
+	}).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_ForceNewDeploymentDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:            "cluster",
+		Service:            "service",
+		NewConfig:          models.TaskConfig{},
+		DryRun:             true,
+		Timeout:            0,
+		NoWait:             false,
+		ForceNewDeployment: true,
+	}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+
+	err := deployer.Deploy(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_RollbackOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		DryRun:            false,
+		Timeout:           0,
+		NoWait:            false,
+		RollbackOnFailure: true,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newTaskDefiniton := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	rolledBackService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefiniton).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(assert.AnError)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, &types.TaskDefinition{TaskDefinitionArn: service.TaskDefinition}).Return(rolledBackService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, rolledBackService, input.Timeout, gomock.Any()).Return(nil)
 
 	err := deployer.Deploy(context.Background(), input)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Nil(t, rollbackErr.RollbackCause)
+	assert.Equal(t, "arn::task:1", rollbackErr.TaskDefinition)
+	assert.Equal(t, strings.Join([]string{
+		"service did not reflect changes, cause: assert.AnError general error for testing",
+		"the service was rolled back to arn::task:1",
+	}, "\n"), err.Error())
+}
+
+func Test_Deployer_RollbackOnFailureFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		DryRun:            false,
+		Timeout:           0,
+		NoWait:            false,
+		RollbackOnFailure: true,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newTaskDefiniton := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefiniton).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(assert.AnError)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, &types.TaskDefinition{TaskDefinitionArn: service.TaskDefinition}).Return(nil, assert.AnError)
+
+	err := deployer.Deploy(context.Background(), input)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Equal(t, "unable to update service, cause: assert.AnError general error for testing", rollbackErr.RollbackCause.Error())
+}
+
+func Test_Deployer_ConfirmDeclined(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	var question string
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Confirm: func(q string) (bool, error) {
+			question = q
+			return false, nil
+		},
+	}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+	assert.Equal(t, "go ahead and update service?", question)
+}
+
+func Test_Deployer_Confirmed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		NoWait:  true,
+		Confirm: func(string) (bool, error) { return true, nil },
+	}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newTaskDefiniton := &types.TaskDefinition{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefiniton, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefiniton).Return(&types.Service{}, nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_ExistingTaskDefinition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:        "cluster",
+		Service:        "service",
+		TaskDefinition: "task:1",
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	targetOutput := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:1")},
+	}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("512")})
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "task:1").Return(targetOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(targetOutput).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, targetOutput.TaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_ExistingTaskDefinitionWithTheSameConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:        "cluster",
+		Service:        "service",
+		TaskDefinition: "arn::task:1",
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitonOutput := &ecs.DescribeTaskDefinitionOutput{}
+	targetOutput := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:1")},
+	}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitonOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitonOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "arn::task:1").Return(targetOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(targetOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, targetOutput.TaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
 	assert.Nil(t, err)
 }
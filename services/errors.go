@@ -0,0 +1,45 @@
+package services
+
+import "fmt"
+
+// RollbackError is returned when a deploy failed and we pointed the service
+// back to the task definition it had before the deploy
+type RollbackError struct {
+	// Cause is the error that made the deploy fail
+	Cause error
+	// TaskDefinition is the task definition we rolled back to
+	TaskDefinition string
+	// RollbackCause is the error we found while rolling back, nil if the rollback succeeded
+	RollbackCause error
+}
+
+func (err *RollbackError) Error() string {
+	if err.RollbackCause != nil {
+		return fmt.Sprintf(
+			"%s\nwe also failed to roll back to %s, cause: %s",
+			err.Cause, err.TaskDefinition, err.RollbackCause,
+		)
+	}
+	return fmt.Sprintf("%s\nthe service was rolled back to %s", err.Cause, err.TaskDefinition)
+}
+
+func (err *RollbackError) Unwrap() error {
+	return err.Cause
+}
+
+// CanaryError is returned when the canary of a deploy failed, the main
+// service was left untouched even if Cause is a *RollbackError of the canary
+type CanaryError struct {
+	// Canary is the name of the canary service
+	Canary string
+	// Cause is the error that made the canary fail
+	Cause error
+}
+
+func (err *CanaryError) Error() string {
+	return err.Cause.Error()
+}
+
+func (err *CanaryError) Unwrap() error {
+	return err.Cause
+}
@@ -0,0 +1,113 @@
+package services
+
+//go:generate mockgen -destination=mocks/hooks.go . HookRunner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// HookRunner runs the hook commands of a deploy
+type HookRunner interface {
+	// RunHook runs the command with the extra environment variables, writing its output to output
+	RunHook(ctx context.Context, command string, env []string, output io.Writer) error
+}
+
+type shellHookRunner struct{}
+
+// NewShellHookRunner creates a HookRunner that runs the hooks through sh
+func NewShellHookRunner() HookRunner {
+	return &shellHookRunner{}
+}
+
+func (r *shellHookRunner) RunHook(ctx context.Context, command string, env []string, output io.Writer) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// hookEnv is what the hooks get to know about the deploy
+type hookEnv struct {
+	cluster           string
+	service           string
+	oldTaskDefinition string
+	newTaskDefinition string
+	diff              *models.Change
+}
+
+// environ describes the deploy to the given hook as environment variables
+func (env *hookEnv) environ(hook string, cause error) ([]string, error) {
+	diff, err := json.Marshal(env.diff)
+	if err != nil {
+		return nil, err
+	}
+	vars := []string{
+		"ECS_SHIP_HOOK=" + hook,
+		"ECS_SHIP_CLUSTER=" + env.cluster,
+		"ECS_SHIP_SERVICE=" + env.service,
+		"ECS_SHIP_OLD_TASK_DEFINITION=" + env.oldTaskDefinition,
+		"ECS_SHIP_NEW_TASK_DEFINITION=" + env.newTaskDefinition,
+		"ECS_SHIP_DIFF=" + string(diff),
+	}
+	if cause != nil {
+		vars = append(vars, "ECS_SHIP_ERROR="+cause.Error())
+	}
+	return vars, nil
+}
+
+// mergeChanges puts the non empty changes under a single root
+func mergeChanges(changes ...*models.Change) *models.Change {
+	merged := &models.Change{}
+	for _, change := range changes {
+		if !change.Empty() {
+			merged.Children = append(merged.Children, change)
+		}
+	}
+	return merged
+}
+
+func (s *deployerService) runHook(ctx context.Context, logger *log.Logger, name string, command string, env *hookEnv, cause error) error {
+	vars, err := env.environ(name, cause)
+	if err != nil {
+		return errorx.Decorate(err, "unable to describe the deploy to the %s hook", name)
+	}
+	logger.Printf("running the %s hook\n", name)
+	if err := s.hooks.RunHook(ctx, command, vars, logger.Writer()); err != nil {
+		return errorx.Decorate(err, "the %s hook failed", name)
+	}
+	return nil
+}
+
+// runPreHook runs a hook that can stop the deploy by failing
+func (s *deployerService) runPreHook(ctx context.Context, logger *log.Logger, input *DeployInput, name string, command string, env *hookEnv) error {
+	if command == "" {
+		return nil
+	}
+	if err := s.runHook(ctx, logger, name, command, env, nil); err != nil {
+		return errorx.Decorate(err, "aborting the deploy, %s was not updated", input.Service)
+	}
+	return nil
+}
+
+// runPostHook runs a hook once the deploy is over, its failure doesn't change
+// the outcome of the deploy. It also runs when the deploy was interrupted.
+func (s *deployerService) runPostHook(ctx context.Context, logger *log.Logger, name string, command string, env *hookEnv, cause error) {
+	if command == "" {
+		return
+	}
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	if err := s.runHook(ctx, logger, name, command, env, cause); err != nil {
+		logger.Println(color.YellowString("%s", err))
+	}
+}
@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	mock_services "github.com/adroll/ecs-ship/services/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ShellHookRunner(t *testing.T) {
+	var output bytes.Buffer
+	err := services.NewShellHookRunner().RunHook(
+		context.Background(), `echo "$ECS_SHIP_SERVICE"; exit 3`, []string{"ECS_SHIP_SERVICE=web"}, &output,
+	)
+	assert.Equal(t, "exit status 3", err.Error())
+	assert.Equal(t, "web\n", output.String())
+}
+
+func Test_Deployer_Hooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockHooks := mock_services.NewMockHookRunner(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithHookRunner(mockHooks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Hooks: models.HooksConfig{
+			PreRegister: "./pre-register.sh",
+			PreUpdate:   "./pre-update.sh",
+			PostSuccess: "./post-success.sh",
+			PostFailure: "./post-failure.sh",
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	gomock.InOrder(
+		mockHooks.EXPECT().RunHook(ctx, "./pre-register.sh", []string{
+			"ECS_SHIP_HOOK=preRegister",
+			"ECS_SHIP_CLUSTER=cluster",
+			"ECS_SHIP_SERVICE=service",
+			"ECS_SHIP_OLD_TASK_DEFINITION=arn::task:1",
+			"ECS_SHIP_NEW_TASK_DEFINITION=",
+			`ECS_SHIP_DIFF=[{"path":"cpu","kind":"modified","isNow":"256"}]`,
+		}, gomock.Any()).Return(nil),
+		mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil),
+		mockHooks.EXPECT().RunHook(ctx, "./pre-update.sh", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, env []string, _ io.Writer) error {
+				assert.Contains(t, env, "ECS_SHIP_NEW_TASK_DEFINITION=arn::task:2")
+				return nil
+			}),
+		mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil),
+		mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil),
+		mockHooks.EXPECT().RunHook(gomock.Any(), "./post-success.sh", gomock.Any(), gomock.Any()).Return(assert.AnError),
+	)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_PreHookVetoesTheDeploy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockHooks := mock_services.NewMockHookRunner(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithHookRunner(mockHooks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Hooks: models.HooksConfig{
+			PreRegister: "./pre-register.sh",
+			PostFailure: "./post-failure.sh",
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockHooks.EXPECT().RunHook(ctx, "./pre-register.sh", gomock.Any(), gomock.Any()).Return(assert.AnError)
+	mockHooks.EXPECT().RunHook(gomock.Any(), "./post-failure.sh", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, env []string, _ io.Writer) error {
+			assert.Contains(t, env, "ECS_SHIP_ERROR=aborting the deploy, service was not updated, "+
+				"cause: the preRegister hook failed, cause: assert.AnError general error for testing")
+			return nil
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "aborting the deploy, service was not updated, "+
+		"cause: the preRegister hook failed, cause: assert.AnError general error for testing", err.Error())
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// cleanupTimeout bounds the work we still do once a deploy is over, which
+// can't be cut short by the cancellation of the deploy
+const cleanupTimeout = 2 * time.Minute
+
+// cleanupContext keeps the values of ctx but neither its cancellation nor its
+// deadline, so the cleanup runs even after an interruption
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+}
+
+// interrupted reports a deploy that was cancelled while the service was
+// moving to the revision, and rolls the service back when asked to. The
+// context is done by now, so the rollback runs without its cancellation.
+func (s *deployerService) interrupted(ctx context.Context, logger *log.Logger, input *DeployInput, service *types.Service, revision string) error {
+	logger.Println(color.YellowString("deployment was left in progress at revision %s", revision))
+	err := errorx.Decorate(ctx.Err(), "the deploy of %s was interrupted", aws.ToString(service.ServiceName))
+	if !input.RollbackOnInterrupt {
+		return err
+	}
+	return s.rollback(context.WithoutCancel(ctx), logger, service, input.Timeout, err)
+}
+
+// interruptedCodeDeploy reports a blue/green deploy that was cancelled while
+// CodeDeploy was running the deployment, and stops it when asked to roll back
+func (s *deployerService) interruptedCodeDeploy(ctx context.Context, logger *log.Logger, input *DeployInput, service *types.Service, deploymentID string, revision string) error {
+	logger.Println(color.YellowString("deployment %s was left in progress at revision %s", deploymentID, revision))
+	err := errorx.Decorate(ctx.Err(), "the deploy of %s was interrupted", aws.ToString(service.ServiceName))
+	if !input.RollbackOnInterrupt {
+		return err
+	}
+	rollbackErr := &RollbackError{Cause: err, TaskDefinition: aws.ToString(service.TaskDefinition)}
+	logger.Printf("stopping the deployment %s, CodeDeploy rolls it back\n", deploymentID)
+	if err := s.codeDeploy.StopDeployment(context.WithoutCancel(ctx), deploymentID); err != nil {
+		rollbackErr.RollbackCause = err
+	}
+	return rollbackErr
+}
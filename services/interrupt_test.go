@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	mock_services "github.com/adroll/ecs-ship/services/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_Interrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Timeout: time.Minute,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *types.Service, _ time.Duration, _ io.Writer) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "the deploy of service was interrupted")
+}
+
+func Test_Deployer_InterruptedRunsPostFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockHooks := mock_services.NewMockHookRunner(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithHookRunner(mockHooks))
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Timeout: time.Minute,
+		Hooks:   models.HooksConfig{PostFailure: "./post-failure.sh"},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *types.Service, _ time.Duration, _ io.Writer) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	mockHooks.EXPECT().RunHook(gomock.Any(), "./post-failure.sh", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, env []string, _ io.Writer) error {
+			assert.Nil(t, ctx.Err())
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			assert.Contains(t, env, "ECS_SHIP_ERROR=the deploy of service was interrupted, cause: context canceled")
+			return nil
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Deployer_RollbackOnInterrupt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		Timeout:             time.Minute,
+		RollbackOnInterrupt: true,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *types.Service, _ time.Duration, _ io.Writer) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	rolledBackService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().UpdateTaskDefinition(gomock.Any(), service, &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:1")}).
+		DoAndReturn(func(ctx context.Context, _ *types.Service, _ *types.TaskDefinition) (*types.Service, error) {
+			assert.Nil(t, ctx.Err())
+			return rolledBackService, nil
+		})
+	mockClient.EXPECT().WaitForServiceToLookGood(gomock.Any(), rolledBackService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	var rollbackErr *services.RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	assert.Nil(t, rollbackErr.RollbackCause)
+	assert.ErrorIs(t, err, context.Canceled)
+}
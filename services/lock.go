@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// lock takes the deploy lock of the service and returns the function that
+// gives it back
+func (s *deployerService) lock(ctx context.Context, logger *log.Logger, cluster string, name string, service *types.Service, owner string, breakLock bool) (func(), error) {
+	key := aws.ToString(service.ServiceArn)
+	if key == "" {
+		key = fmt.Sprintf("%s/%s", cluster, name)
+	}
+	if owner == "" {
+		owner = "ecs-ship"
+	}
+
+	if breakLock {
+		logger.Println(color.YellowString("breaking the lock of %s", key))
+		if err := s.locks.Break(ctx, key); err != nil {
+			return nil, errorx.Decorate(err, "unable to break the lock")
+		}
+	}
+	if err := s.locks.Acquire(ctx, key, owner); err != nil {
+		var heldErr *clients.LockHeldError
+		if errors.As(err, &heldErr) {
+			return nil, errorx.Decorate(err, "another deploy of %s is in progress", name)
+		}
+		return nil, errorx.Decorate(err, "unable to take the lock of %s", name)
+	}
+	logger.Printf("%s is locked by %s until the deploy is over\n", key, owner)
+
+	return func() {
+		// the lock has to go even if the deploy was interrupted
+		ctx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if err := s.locks.Release(ctx, key, owner); err != nil {
+			logger.Println(color.YellowString("unable to release the lock of %s: %s", key, err))
+		}
+	}, nil
+}
@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adroll/ecs-ship/clients"
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_LockHeldUntilTheWaitIsOver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockLocks := mock_clients.NewMockLockStore(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithLockStore(mockLocks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		LockOwner: "bob",
+		BreakLock: true,
+	}
+
+	service := &types.Service{
+		ServiceArn:     aws.String("arn::service"),
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	// a deploy that held the lock moved the service on while we waited for it
+	lockedService := &types.Service{
+		ServiceArn:     aws.String("arn::service"),
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:2"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	newTaskDefinition := &types.TaskDefinition{}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	gomock.InOrder(
+		mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil),
+		mockLocks.EXPECT().Break(ctx, "arn::service").Return(nil),
+		mockLocks.EXPECT().Acquire(ctx, "arn::service", "bob").Return(nil),
+		mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(lockedService, nil),
+		mockClient.EXPECT().DoesServiceLookGood(ctx, lockedService).Return(true, nil),
+		mockClient.EXPECT().GetTaskDefinition(ctx, lockedService).Return(taskDefinitionOutput, nil),
+		mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{}),
+		mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil),
+		mockClient.EXPECT().UpdateTaskDefinition(ctx, lockedService, newTaskDefinition).Return(newService, nil),
+		mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil),
+		mockLocks.EXPECT().Release(gomock.Any(), "arn::service", "bob").Return(nil),
+	)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_LockedByAnotherDeploy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockLocks := mock_clients.NewMockLockStore(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithLockStore(mockLocks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		LockOwner: "bob",
+	}
+
+	service := &types.Service{
+		ServiceArn:  aws.String("arn::service"),
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	held := &clients.LockHeldError{
+		Key:  "arn::service",
+		Lock: clients.Lock{Owner: "alice", AcquiredAt: time.Now().Add(-time.Minute)},
+	}
+	mockLocks.EXPECT().Acquire(ctx, "arn::service", "bob").Return(held)
+
+	err := deployer.Deploy(ctx, input)
+	var lockErr *clients.LockHeldError
+	assert.ErrorAs(t, err, &lockErr)
+	assert.Contains(t, err.Error(), "another deploy of service is in progress, cause: arn::service is locked by alice since")
+}
+
+func Test_Deployer_UnableToLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockLocks := mock_clients.NewMockLockStore(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithLockStore(mockLocks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		LockOwner: "bob",
+	}
+
+	service := &types.Service{
+		ServiceArn:  aws.String("arn::service"),
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockLocks.EXPECT().Acquire(ctx, "arn::service", "bob").Return(assert.AnError)
+
+	err := deployer.Deploy(ctx, input)
+	assert.EqualError(t, err, "unable to take the lock of service, cause: assert.AnError general error for testing")
+}
+
+func Test_Deployer_DryRunDoesntLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockLocks := mock_clients.NewMockLockStore(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithLockStore(mockLocks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		DryRun: true,
+	}
+
+	service := &types.Service{
+		ServiceArn:  aws.String("arn::service"),
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
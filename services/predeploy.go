@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// runPreDeployTask runs the one-off task of the deploy with the new task
+// definition, in the network and capacity the service is about to get, and
+// fails if any of its essential containers didn't exit cleanly
+func (s *deployerService) runPreDeployTask(ctx context.Context, logger *log.Logger, input *DeployInput, service *types.Service, serviceInput *ecs.UpdateServiceInput, taskDefinition *types.TaskDefinition) error {
+	config := input.PreDeployTask
+	networkConfiguration := service.NetworkConfiguration
+	if serviceInput.NetworkConfiguration != nil {
+		networkConfiguration = serviceInput.NetworkConfiguration
+	}
+	launchType := service.LaunchType
+	strategy := service.CapacityProviderStrategy
+	if serviceInput.CapacityProviderStrategy != nil {
+		// tasks can't have both
+		launchType = ""
+		strategy = serviceInput.CapacityProviderStrategy
+	}
+	runTaskInput := &ecs.RunTaskInput{
+		Cluster:                  service.ClusterArn,
+		TaskDefinition:           taskDefinition.TaskDefinitionArn,
+		NetworkConfiguration:     networkConfiguration,
+		LaunchType:               launchType,
+		CapacityProviderStrategy: strategy,
+		PlatformVersion:          service.PlatformVersion,
+		StartedBy:                aws.String("ecs-ship"),
+	}
+	var container string
+	if len(config.Command) > 0 {
+		container = config.Container
+		if container == "" && len(taskDefinition.ContainerDefinitions) > 0 {
+			container = aws.ToString(taskDefinition.ContainerDefinitions[0].Name)
+		}
+		runTaskInput.Overrides = &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String(container), Command: config.Command},
+			},
+		}
+	}
+
+	logger.Printf("running the pre-deploy task with %s\n", *taskDefinition.TaskDefinitionArn)
+	task, err := s.client.RunTask(ctx, runTaskInput)
+	if err != nil {
+		return errorx.Decorate(err, "unable to run the pre-deploy task")
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = input.Timeout
+	}
+	logger.Printf("waiting for the task %s to stop\n", aws.ToString(task.TaskArn))
+	stoppedTask, err := s.client.WaitForTaskToStop(ctx, aws.ToString(service.ClusterArn), aws.ToString(task.TaskArn), timeout, progress(logger))
+	if err != nil {
+		err = errorx.Decorate(err, "unable to wait for the pre-deploy task")
+		return s.stopPreDeployTask(ctx, logger, aws.ToString(service.ClusterArn), aws.ToString(task.TaskArn), err)
+	}
+
+	if err := taskFailure(stoppedTask, judgedContainers(taskDefinition, container)); err != nil {
+		return err
+	}
+	logger.Println(color.GreenString("the pre-deploy task finished successfully"))
+	return nil
+}
+
+// stopPreDeployTask stops the pre-deploy task we gave up waiting for, so it
+// doesn't keep running against a service that won't get its task definition
+func (s *deployerService) stopPreDeployTask(ctx context.Context, logger *log.Logger, cluster string, taskArn string, cause error) error {
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	logger.Printf("stopping the task %s\n", taskArn)
+	if err := s.client.StopTask(ctx, cluster, taskArn, "ecs-ship stopped waiting for the pre-deploy task"); err != nil {
+		return fmt.Errorf("%w\nwe also failed to stop %s, cause: %s", cause, taskArn, err)
+	}
+	return cause
+}
+
+// judgedContainers are the containers whose exit code tells if the task
+// worked: the essential ones and the one running the overridden command.
+// Sidecars that are killed when the task stops don't count.
+func judgedContainers(taskDefinition *types.TaskDefinition, overridden string) map[string]bool {
+	judged := map[string]bool{}
+	for _, definition := range taskDefinition.ContainerDefinitions {
+		if definition.Essential == nil || *definition.Essential {
+			judged[aws.ToString(definition.Name)] = true
+		}
+	}
+	if overridden != "" {
+		judged[overridden] = true
+	}
+	return judged
+}
+
+// taskFailure explains why a stopped task failed, nil if all of its judged
+// containers exited with 0
+func taskFailure(task *types.Task, judged map[string]bool) error {
+	var failures []string
+	for _, container := range task.Containers {
+		name := aws.ToString(container.Name)
+		if !judged[name] {
+			continue
+		}
+		switch {
+		case container.ExitCode == nil:
+			failures = append(failures, fmt.Sprintf("container %s exited without an exit code", name))
+		case *container.ExitCode != 0:
+			failures = append(failures, fmt.Sprintf("container %s exited with %d", name, *container.ExitCode))
+		}
+	}
+	if len(task.Containers) == 0 {
+		failures = append(failures, "the task has no containers")
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"the pre-deploy task %s failed: %s (%s)",
+		aws.ToString(task.TaskArn), strings.Join(failures, ", "), aws.ToString(task.StoppedReason),
+	)
+}
+
+// abortAfterPreDeployTask optionally deregisters the task definition the
+// failed pre-deploy task ran with
+func (s *deployerService) abortAfterPreDeployTask(ctx context.Context, logger *log.Logger, input *DeployInput, taskDefinition *types.TaskDefinition, cause error) error {
+	if !input.PreDeployTask.DeregisterOnFailure {
+		return cause
+	}
+	logger.Printf("deregistering %s\n", *taskDefinition.TaskDefinitionArn)
+	if err := s.client.DeregisterTaskDefinition(ctx, *taskDefinition.TaskDefinitionArn); err != nil {
+		return fmt.Errorf("%w\nwe also failed to deregister %s, cause: %s", cause, *taskDefinition.TaskDefinitionArn, err)
+	}
+	return cause
+}
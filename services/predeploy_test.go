@@ -0,0 +1,438 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_PreDeployTaskSucceeds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{Command: []string{"migrate"}},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{
+		TaskDefinitionArn: aws.String("arn::task:2"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	task := &types.Task{TaskArn: aws.String("arn::task/abc")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:              service.ClusterArn,
+		TaskDefinition:       newTaskDefinition.TaskDefinitionArn,
+		NetworkConfiguration: service.NetworkConfiguration,
+		LaunchType:           types.LaunchTypeFargate,
+		StartedBy:            aws.String("ecs-ship"),
+		Overrides: &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String("web"), Command: []string{"migrate"}},
+			},
+		},
+	}).Return(task, nil)
+
+	stoppedTask := &types.Task{
+		TaskArn:    task.TaskArn,
+		Containers: []types.Container{{Name: aws.String("web"), ExitCode: aws.Int32(0)}},
+	}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().WaitForTaskToStop(ctx, "arn::cluster", "arn::task/abc", input.Timeout, gomock.Any()).Return(stoppedTask, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_PreDeployTaskFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{
+			Container:           "web",
+			Command:             []string{"migrate"},
+			DeregisterOnFailure: true,
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{
+		TaskDefinitionArn: aws.String("arn::task:2"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	task := &types.Task{TaskArn: aws.String("arn::task/abc")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:              service.ClusterArn,
+		TaskDefinition:       newTaskDefinition.TaskDefinitionArn,
+		NetworkConfiguration: service.NetworkConfiguration,
+		LaunchType:           types.LaunchTypeFargate,
+		StartedBy:            aws.String("ecs-ship"),
+		Overrides: &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String("web"), Command: []string{"migrate"}},
+			},
+		},
+	}).Return(task, nil)
+
+	stoppedTask := &types.Task{
+		TaskArn:       task.TaskArn,
+		StoppedReason: aws.String("Essential container in task exited"),
+		Containers:    []types.Container{{Name: aws.String("web"), ExitCode: aws.Int32(1)}},
+	}
+	mockClient.EXPECT().WaitForTaskToStop(ctx, "arn::cluster", "arn::task/abc", input.Timeout, gomock.Any()).Return(stoppedTask, nil)
+	mockClient.EXPECT().DeregisterTaskDefinition(ctx, "arn::task:2").Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, strings.Join([]string{
+		"aborting the deploy, service was not updated, cause: the pre-deploy task arn::task/abc failed:",
+		"container web exited with 1 (Essential container in task exited)",
+	}, " "), err.Error())
+}
+
+func Test_Deployer_PreDeployTaskIgnoresSidecars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{Command: []string{"migrate"}},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{
+		TaskDefinitionArn: aws.String("arn::task:2"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	task := &types.Task{TaskArn: aws.String("arn::task/abc")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:              service.ClusterArn,
+		TaskDefinition:       newTaskDefinition.TaskDefinitionArn,
+		NetworkConfiguration: service.NetworkConfiguration,
+		LaunchType:           types.LaunchTypeFargate,
+		StartedBy:            aws.String("ecs-ship"),
+		Overrides: &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String("web"), Command: []string{"migrate"}},
+			},
+		},
+	}).Return(task, nil)
+	newTaskDefinition.ContainerDefinitions = append(newTaskDefinition.ContainerDefinitions,
+		types.ContainerDefinition{Name: aws.String("log-router"), Essential: aws.Bool(false)},
+	)
+
+	// the sidecar is killed once the essential container is done
+	stoppedTask := &types.Task{
+		TaskArn: task.TaskArn,
+		Containers: []types.Container{
+			{Name: aws.String("web"), ExitCode: aws.Int32(0)},
+			{Name: aws.String("log-router"), ExitCode: aws.Int32(137)},
+		},
+	}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().WaitForTaskToStop(ctx, "arn::cluster", "arn::task/abc", input.Timeout, gomock.Any()).Return(stoppedTask, nil)
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_PreDeployTaskTimesOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{Command: []string{"migrate"}},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{
+		TaskDefinitionArn: aws.String("arn::task:2"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	task := &types.Task{TaskArn: aws.String("arn::task/abc")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:              service.ClusterArn,
+		TaskDefinition:       newTaskDefinition.TaskDefinitionArn,
+		NetworkConfiguration: service.NetworkConfiguration,
+		LaunchType:           types.LaunchTypeFargate,
+		StartedBy:            aws.String("ecs-ship"),
+		Overrides: &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String("web"), Command: []string{"migrate"}},
+			},
+		},
+	}).Return(task, nil)
+
+	mockClient.EXPECT().WaitForTaskToStop(ctx, "arn::cluster", "arn::task/abc", input.Timeout, gomock.Any()).
+		Return(nil, errors.New("timeout"))
+	mockClient.EXPECT().StopTask(gomock.Any(), "arn::cluster", "arn::task/abc", gomock.Any()).Return(nil)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "aborting the deploy, service was not updated, "+
+		"cause: unable to wait for the pre-deploy task, cause: timeout", err.Error())
+}
+
+func Test_Deployer_PreDeployTaskInterrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{Command: []string{"migrate"}},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{
+		TaskDefinitionArn: aws.String("arn::task:2"),
+		ContainerDefinitions: []types.ContainerDefinition{
+			{Name: aws.String("web")},
+		},
+	}
+	task := &types.Task{TaskArn: aws.String("arn::task/abc")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:              service.ClusterArn,
+		TaskDefinition:       newTaskDefinition.TaskDefinitionArn,
+		NetworkConfiguration: service.NetworkConfiguration,
+		LaunchType:           types.LaunchTypeFargate,
+		StartedBy:            aws.String("ecs-ship"),
+		Overrides: &types.TaskOverride{
+			ContainerOverrides: []types.ContainerOverride{
+				{Name: aws.String("web"), Command: []string{"migrate"}},
+			},
+		},
+	}).Return(task, nil)
+
+	mockClient.EXPECT().WaitForTaskToStop(ctx, "arn::cluster", "arn::task/abc", input.Timeout, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ string, _ time.Duration, _ io.Writer) (*types.Task, error) {
+			cancel()
+			return nil, ctx.Err()
+		})
+	mockClient.EXPECT().StopTask(gomock.Any(), "arn::cluster", "arn::task/abc", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ string, _ string) error {
+			assert.Nil(t, ctx.Err())
+			return nil
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Deployer_PreDeployTaskInTheNewNetwork(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		ServiceConfig: models.ServiceConfig{
+			NetworkConfiguration: &models.NetworkConfig{
+				AwsvpcConfiguration: &models.AwsVpcConfig{
+					Subnets: &models.ListPatch{Add: []string{"subnet-b"}, Remove: []string{"subnet-a"}},
+				},
+			},
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{Subnets: []string{"subnet-a"}},
+		},
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, runTaskInput *ecs.RunTaskInput) (*types.Task, error) {
+			assert.Equal(t, []string{"subnet-b"}, runTaskInput.NetworkConfiguration.AwsvpcConfiguration.Subnets)
+			return nil, assert.AnError
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.Equal(t, "aborting the deploy, service was not updated, "+
+		"cause: unable to run the pre-deploy task, cause: assert.AnError general error for testing", err.Error())
+}
+
+func Test_Deployer_PreDeployTaskWithTheNewCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		ServiceConfig: models.ServiceConfig{
+			CapacityProviderStrategy: []models.CapacityProviderConfig{
+				{CapacityProvider: "FARGATE_SPOT", Weight: 1},
+			},
+		},
+		PreDeployTask: &models.PreDeployTaskConfig{},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+		LaunchType:     types.LaunchTypeFargate,
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil)
+	mockClient.EXPECT().RunTask(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, runTaskInput *ecs.RunTaskInput) (*types.Task, error) {
+			assert.Equal(t, types.LaunchType(""), runTaskInput.LaunchType)
+			assert.Equal(t, []types.CapacityProviderStrategyItem{
+				{CapacityProvider: aws.String("FARGATE_SPOT"), Weight: 1},
+			}, runTaskInput.CapacityProviderStrategy)
+			return nil, assert.AnError
+		})
+
+	err := deployer.Deploy(ctx, input)
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/adroll/ecs-ship/clients"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/joomcode/errorx"
+)
+
+// PromoteInput represents the input for the PromoterService
+type PromoteInput struct {
+	// FromCluster is the name of the ECS cluster of the source service
+	FromCluster string
+	// FromService is the name of the ECS service whose images we promote
+	FromService string
+	// Containers are the containers whose images we promote, empty means all of them
+	Containers []string
+	// Environment are the environment variables whose values we promote along with the images
+	Environment []string
+	// Deploy is the deploy of the destination service, its NewConfig is replaced with what we promote
+	Deploy *DeployInput
+}
+
+// PromoterService is the interface for deploying what a service runs to another service
+type PromoterService interface {
+	// Promote will deploy the images of the source service to the destination service
+	Promote(ctx context.Context, input *PromoteInput) error
+}
+
+type promoterService struct {
+	source   clients.ECSClient
+	deployer DeployerService
+}
+
+// NewPromoterService creates a new PromoterService, the source client reaches
+// the source service, which can live in another region or account than the
+// services of the deployer
+func NewPromoterService(source clients.ECSClient, deployer DeployerService) PromoterService {
+	return &promoterService{source: source, deployer: deployer}
+}
+
+func (s *promoterService) Promote(ctx context.Context, input *PromoteInput) error {
+	logger := input.Deploy.logger()
+	logger.Printf("promoting from service:\n  cluster: %s\n  service: %s\n", input.FromCluster, input.FromService)
+	service, err := s.source.GetService(ctx, input.FromCluster, input.FromService)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get source service")
+	}
+	output, err := s.source.GetTaskDefinition(ctx, service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get source task definition")
+	}
+
+	config, err := models.NewTaskConfigFromTaskDefinition(output.TaskDefinition, input.Containers, input.Environment)
+	if err != nil {
+		return errorx.Decorate(err, "unable to promote %s", aws.ToString(output.TaskDefinition.TaskDefinitionArn))
+	}
+
+	logger.Printf("the source service runs %s with these images:\n", aws.ToString(output.TaskDefinition.TaskDefinitionArn))
+	names := make([]string, 0, len(config.ContainerDefinitions))
+	for name := range config.ContainerDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Printf("  %s: %s\n", name, aws.ToString(config.ContainerDefinitions[name].Image))
+	}
+
+	input.Deploy.NewConfig = *config
+	// the containers we were asked for by name have to be in the destination too
+	input.Deploy.RequireContainers = len(input.Containers) > 0
+	return s.deployer.Deploy(ctx, input.Deploy)
+}
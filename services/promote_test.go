@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	mock_services "github.com/adroll/ecs-ship/services/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Promoter_Promote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockSource := mock_clients.NewMockECSClient(ctrl)
+	mockDeployer := mock_services.NewMockDeployerService(ctrl)
+	promoter := services.NewPromoterService(mockSource, mockDeployer)
+	ctx := context.Background()
+	input := &services.PromoteInput{
+		FromCluster: "staging",
+		FromService: "web",
+		Containers:  []string{"web"},
+		Environment: []string{"VERSION"},
+		Deploy:      &services.DeployInput{Cluster: "production", Service: "web"},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("web"),
+		ClusterArn:     aws.String("arn::staging"),
+		TaskDefinition: aws.String("arn::web-staging:12"),
+	}
+	mockSource.EXPECT().GetService(ctx, "staging", "web").Return(service, nil)
+	mockSource.EXPECT().GetTaskDefinition(ctx, service).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String("arn::web-staging:12"),
+			ContainerDefinitions: []types.ContainerDefinition{
+				{
+					Name:  aws.String("web"),
+					Image: aws.String("web:v2"),
+					Environment: []types.KeyValuePair{
+						{Name: aws.String("VERSION"), Value: aws.String("2")},
+						{Name: aws.String("DATABASE_URL"), Value: aws.String("staging")},
+					},
+				},
+				{Name: aws.String("sidecar"), Image: aws.String("sidecar:staging")},
+			},
+		},
+	}, nil)
+	mockDeployer.EXPECT().Deploy(ctx, input.Deploy).DoAndReturn(func(_ context.Context, deploy *services.DeployInput) error {
+		assert.Equal(t, models.TaskConfig{
+			ContainerDefinitions: map[string]models.ContainerConfig{
+				"web": {Image: aws.String("web:v2"), Environment: map[string]string{"VERSION": "2"}},
+			},
+		}, deploy.NewConfig)
+		assert.True(t, deploy.RequireContainers)
+		return nil
+	})
+
+	err := promoter.Promote(ctx, input)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// Retention says which revisions of a task definition family survive a prune
+type Retention struct {
+	// Keep is how many of the newest ACTIVE revisions are never pruned
+	Keep int
+	// OlderThan only prunes revisions registered longer ago than this, zero means any age
+	OlderThan time.Duration
+	// Delete also deletes the revisions once they're deregistered
+	Delete bool
+}
+
+// validate rejects retentions that can't be applied
+func (r Retention) validate() error {
+	if r.Keep < 0 {
+		return fmt.Errorf("can't keep %d revisions, it has to be zero or more", r.Keep)
+	}
+	return nil
+}
+
+// PruneInput represents the input for pruning the revisions of a service's family
+type PruneInput struct {
+	// Cluster is the name of the ECS cluster
+	Cluster string
+	// Service is the name of the ECS service whose family we prune
+	Service string
+	// Retention says which revisions we keep
+	Retention Retention
+	// DryRun will only list the revisions we would prune
+	DryRun bool
+	// Logger is where we report the progress of the prune, nil means the standard logger
+	Logger *log.Logger
+}
+
+func (input *PruneInput) logger() *log.Logger {
+	if input.Logger != nil {
+		return input.Logger
+	}
+	return log.Default()
+}
+
+func (s *deployerService) Prune(ctx context.Context, input *PruneInput) error {
+	if err := input.Retention.validate(); err != nil {
+		return err
+	}
+	logger := input.logger()
+	logger.Printf("pruning task definitions:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
+	service, err := s.client.GetService(ctx, input.Cluster, input.Service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get service")
+	}
+	output, err := s.client.GetTaskDefinition(ctx, service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get task definition")
+	}
+	return s.prune(ctx, logger, input.Cluster, *output.TaskDefinition.Family, input.Retention, input.DryRun)
+}
+
+// prune deregisters, and maybe deletes, the revisions of the family beyond
+// the retention, skipping the ones any service of the cluster uses
+func (s *deployerService) prune(ctx context.Context, logger *log.Logger, cluster string, family string, retention Retention, dryRun bool) error {
+	revisions, err := s.client.ListTaskDefinitionRevisions(ctx, family)
+	if err != nil {
+		return errorx.Decorate(err, "unable to list task definition revisions")
+	}
+	if len(revisions) <= retention.Keep {
+		logger.Printf("%s has %d ACTIVE revisions, there's nothing to prune\n", family, len(revisions))
+		return nil
+	}
+
+	used, err := s.client.ListServiceTaskDefinitions(ctx, cluster)
+	if err != nil {
+		return errorx.Decorate(err, "unable to list the task definitions of the services")
+	}
+	inUse := make(map[string]bool, len(used))
+	for _, arn := range used {
+		inUse[arn] = true
+	}
+
+	var prunable []string
+	for _, arn := range revisions[retention.Keep:] {
+		if inUse[arn] {
+			logger.Printf("keeping %s because a service uses it\n", arn)
+			continue
+		}
+		if retention.OlderThan > 0 {
+			output, err := s.client.DescribeTaskDefinition(ctx, arn)
+			if err != nil {
+				return errorx.Decorate(err, "unable to get task definition")
+			}
+			registeredAt := output.TaskDefinition.RegisteredAt
+			if registeredAt == nil || time.Since(*registeredAt) < retention.OlderThan {
+				continue
+			}
+		}
+		prunable = append(prunable, arn)
+	}
+
+	if len(prunable) == 0 {
+		logger.Printf("every revision of %s is within the retention, there's nothing to prune\n", family)
+		return nil
+	}
+
+	action := "deregister"
+	if retention.Delete {
+		action = "deregister and delete"
+	}
+	if dryRun {
+		logger.Printf("we would %s these %d revisions:\n", action, len(prunable))
+		for _, arn := range prunable {
+			logger.Printf("  %s\n", arn)
+		}
+		logger.Println("not proceeding with the prune because this is a dry run :)")
+		return nil
+	}
+
+	logger.Printf("going to %s %d revisions of %s\n", action, len(prunable), family)
+	for _, arn := range prunable {
+		if err := s.client.DeregisterTaskDefinition(ctx, arn); err != nil {
+			return err
+		}
+		logger.Printf("  deregistered %s\n", arn)
+	}
+	if retention.Delete {
+		if err := s.client.DeleteTaskDefinitions(ctx, prunable); err != nil {
+			return err
+		}
+		logger.Printf("deleted %d revisions\n", len(prunable))
+	}
+
+	logger.Println(color.GreenString("%s has been pruned successfully!", family))
+	return nil
+}
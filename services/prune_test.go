@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_Prune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	input := &services.PruneInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		Retention: services.Retention{Keep: 2, Delete: true},
+	}
+	ctx := context.Background()
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::web:6"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{Family: aws.String("web"), Revision: 6},
+	}, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "web").Return([]string{
+		"arn::web:6", "arn::web:5", "arn::web:4", "arn::web:3", "arn::web:2", "arn::web:1",
+	}, nil)
+	// another service still runs web:3
+	mockClient.EXPECT().ListServiceTaskDefinitions(ctx, "cluster").Return([]string{"arn::web:6", "arn::web:3"}, nil)
+
+	mockClient.EXPECT().DeregisterTaskDefinition(ctx, "arn::web:4").Return(nil)
+	mockClient.EXPECT().DeregisterTaskDefinition(ctx, "arn::web:2").Return(nil)
+	mockClient.EXPECT().DeregisterTaskDefinition(ctx, "arn::web:1").Return(nil)
+	mockClient.EXPECT().DeleteTaskDefinitions(ctx, []string{"arn::web:4", "arn::web:2", "arn::web:1"}).Return(nil)
+
+	err := deployer.Prune(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_PruneOlderThan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	input := &services.PruneInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		Retention: services.Retention{Keep: 3, OlderThan: 30 * 24 * time.Hour},
+	}
+	ctx := context.Background()
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::web:6"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{Family: aws.String("web"), Revision: 6},
+	}, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "web").Return([]string{
+		"arn::web:6", "arn::web:5", "arn::web:4", "arn::web:3", "arn::web:2", "arn::web:1",
+	}, nil)
+	// another service still runs web:3
+	mockClient.EXPECT().ListServiceTaskDefinitions(ctx, "cluster").Return([]string{"arn::web:6", "arn::web:3"}, nil)
+
+	registered := func(ago time.Duration) *ecs.DescribeTaskDefinitionOutput {
+		return &ecs.DescribeTaskDefinitionOutput{
+			TaskDefinition: &types.TaskDefinition{RegisteredAt: aws.Time(time.Now().Add(-ago))},
+		}
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "arn::web:2").Return(registered(7*24*time.Hour), nil)
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "arn::web:1").Return(registered(90*24*time.Hour), nil)
+	mockClient.EXPECT().DeregisterTaskDefinition(ctx, "arn::web:1").Return(nil)
+
+	err := deployer.Prune(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_PruneDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	var output bytes.Buffer
+	input := &services.PruneInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		Retention: services.Retention{Keep: 1},
+		DryRun:    true,
+		Logger:    log.New(&output, "", 0),
+	}
+	ctx := context.Background()
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::web:6"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{Family: aws.String("web"), Revision: 6},
+	}, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "web").Return([]string{
+		"arn::web:6", "arn::web:5", "arn::web:4", "arn::web:3", "arn::web:2", "arn::web:1",
+	}, nil)
+	// another service still runs web:3
+	mockClient.EXPECT().ListServiceTaskDefinitions(ctx, "cluster").Return([]string{"arn::web:6", "arn::web:3"}, nil)
+
+	err := deployer.Prune(ctx, input)
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "we would deregister these 4 revisions:\n  arn::web:5\n  arn::web:4\n  arn::web:2\n  arn::web:1\n")
+}
+
+func Test_Deployer_PruneNegativeKeep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+
+	err := deployer.Prune(context.Background(), &services.PruneInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		Retention: services.Retention{Keep: -1},
+	})
+	assert.EqualError(t, err, "can't keep -1 revisions, it has to be zero or more")
+}
+
+func Test_Deployer_DeployNegativeKeep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+
+	err := deployer.Deploy(context.Background(), &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		Prune:   &services.Retention{Keep: -1},
+	})
+	assert.EqualError(t, err, "can't keep -1 revisions, it has to be zero or more")
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// RegisterInput represents the input for registering a task definition that no service uses
+type RegisterInput struct {
+	// TaskDefinition is the family, whose latest ACTIVE revision we patch, or the ARN of a revision
+	TaskDefinition string
+	// NewConfig is the new configuration for the task definition
+	NewConfig models.TaskConfig
+	// DryRun will only show what would change in the task definition
+	DryRun bool
+}
+
+// RegisterOutput describes the revision Register ended up with
+type RegisterOutput struct {
+	// TaskDefinitionArn is the ARN of the revision
+	TaskDefinitionArn string `json:"taskDefinitionArn"`
+	// Family is the task definition family of the revision
+	Family string `json:"family"`
+	// Revision is the number of the revision in its family
+	Revision int32 `json:"revision"`
+	// Registered tells whether we registered the revision or it already had the configuration
+	Registered bool `json:"registered"`
+}
+
+func (s *deployerService) Register(ctx context.Context, input *RegisterInput) (*RegisterOutput, error) {
+	log.Printf("registering task definition:\n  task definition: %s\n", input.TaskDefinition)
+	output, err := s.client.DescribeTaskDefinition(ctx, input.TaskDefinition)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to get task definition")
+	}
+	current := output.TaskDefinition
+
+	oldTaskDefinitionInput := s.client.CopiedTaskDefinition(output)
+	newTaskDefinitionInput := input.NewConfig.ApplyTo(oldTaskDefinitionInput)
+	diff := models.CompareTaskDefinitions(oldTaskDefinitionInput, newTaskDefinitionInput)
+
+	if diff.Empty() {
+		log.Println(color.GreenString("%s already has this configuration, we have nothing to register :d", aws.ToString(current.TaskDefinitionArn)))
+		return &RegisterOutput{
+			TaskDefinitionArn: aws.ToString(current.TaskDefinitionArn),
+			Family:            aws.ToString(current.Family),
+			Revision:          current.Revision,
+		}, nil
+	}
+
+	log.Println("these are the changes:")
+	log.Println(diff)
+
+	if input.DryRun {
+		log.Println("not proceeding with the registration because this is a dry run :)")
+		return nil, nil
+	}
+
+	newTaskDefinition, err := s.client.RegisterTaskDefinition(ctx, newTaskDefinitionInput)
+	if err != nil {
+		return nil, errorx.Decorate(err, "unable to register new task definition")
+	}
+
+	log.Println(color.GreenString("%s has been registered successfully!", aws.ToString(newTaskDefinition.TaskDefinitionArn)))
+	return &RegisterOutput{
+		TaskDefinitionArn: aws.ToString(newTaskDefinition.TaskDefinitionArn),
+		Family:            aws.ToString(newTaskDefinition.Family),
+		Revision:          newTaskDefinition.Revision,
+		Registered:        true,
+	}, nil
+}
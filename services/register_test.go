@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RegisterInput{
+		TaskDefinition: "job",
+		NewConfig:      models.TaskConfig{CPU: aws.String("512")},
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String("arn::job:3"),
+			Family:            aws.String("job"),
+			Revision:          3,
+			Cpu:               aws.String("256"),
+		},
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "job").Return(current, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+	mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, input *ecs.RegisterTaskDefinitionInput) (*types.TaskDefinition, error) {
+			assert.Equal(t, "512", aws.ToString(input.Cpu))
+			return &types.TaskDefinition{
+				TaskDefinitionArn: aws.String("arn::job:4"),
+				Family:            aws.String("job"),
+				Revision:          4,
+			}, nil
+		})
+
+	output, err := deployer.Register(ctx, input)
+	assert.Nil(t, err)
+	assert.Equal(t, &services.RegisterOutput{
+		TaskDefinitionArn: "arn::job:4",
+		Family:            "job",
+		Revision:          4,
+		Registered:        true,
+	}, output)
+}
+
+func Test_Deployer_RegisterWithoutChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RegisterInput{
+		TaskDefinition: "job",
+		NewConfig:      models.TaskConfig{CPU: aws.String("256")},
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String("arn::job:3"),
+			Family:            aws.String("job"),
+			Revision:          3,
+			Cpu:               aws.String("256"),
+		},
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "job").Return(current, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+
+	output, err := deployer.Register(ctx, input)
+	assert.Nil(t, err)
+	assert.Equal(t, &services.RegisterOutput{TaskDefinitionArn: "arn::job:3", Family: "job", Revision: 3}, output)
+}
+
+func Test_Deployer_RegisterDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RegisterInput{
+		TaskDefinition: "job",
+		NewConfig:      models.TaskConfig{CPU: aws.String("512")},
+		DryRun:         true,
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String("arn::job:3"),
+			Family:            aws.String("job"),
+			Revision:          3,
+			Cpu:               aws.String("256"),
+		},
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, "job").Return(current, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+
+	output, err := deployer.Register(ctx, input)
+	assert.Nil(t, err)
+	assert.Nil(t, output)
+}
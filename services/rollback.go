@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adroll/ecs-ship/models"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// RollbackInput represents the input for rolling back a service
+type RollbackInput struct {
+	// Cluster is the name of the ECS cluster
+	Cluster string
+	// Service is the name of the ECS service
+	Service string
+	// Revision is the revision of the family to roll back to, 0 means we use Steps instead
+	Revision int32
+	// Steps is how many ACTIVE revisions we go back from the current one
+	Steps int
+	// DryRun will only show what would change in the remote service
+	DryRun bool
+	// Timeout is the time to wait for the service to be correctly updated
+	Timeout time.Duration
+	// NoWait will disable waiting for updates to be completed
+	NoWait bool
+	// Confirm is asked before updating the service, nil means we don't ask
+	Confirm ConfirmFunc
+	// LockOwner identifies this rollback in the lock of the service
+	LockOwner string
+	// BreakLock drops the lock of the service before taking it, for deploys that died holding it
+	BreakLock bool
+	// CodeDeployApplication is the CodeDeploy application of services using the CODE_DEPLOY controller, empty means AppECS-<cluster>-<service>
+	CodeDeployApplication string
+	// CodeDeployDeploymentGroup is the CodeDeploy deployment group of the service, empty means DgpECS-<cluster>-<service>
+	CodeDeployDeploymentGroup string
+}
+
+// ConfirmFunc asks the user whether we should proceed
+type ConfirmFunc func(question string) (bool, error)
+
+func (s *deployerService) Rollback(ctx context.Context, input *RollbackInput) error {
+	log.Printf("rolling back service:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
+	service, err := s.client.GetService(ctx, input.Cluster, input.Service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get service")
+	}
+	if err := s.checkCodeDeploy(service); err != nil {
+		return err
+	}
+
+	if s.locks != nil && !input.DryRun {
+		unlock, err := s.lock(ctx, log.Default(), input.Cluster, input.Service, service, input.LockOwner, input.BreakLock)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		// a deploy could have changed the service while we waited for the lock
+		service, err = s.client.GetService(ctx, input.Cluster, input.Service)
+		if err != nil {
+			return errorx.Decorate(err, "unable to get service")
+		}
+	}
+
+	current, err := s.client.GetTaskDefinition(ctx, service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get task definition")
+	}
+
+	revisions, err := s.client.ListTaskDefinitionRevisions(ctx, *current.TaskDefinition.Family)
+	if err != nil {
+		return errorx.Decorate(err, "unable to list task definition revisions")
+	}
+
+	var previous []string
+	for _, arn := range revisions {
+		if revisionOf(arn) < current.TaskDefinition.Revision {
+			previous = append(previous, arn)
+		}
+	}
+	if len(previous) == 0 {
+		return fmt.Errorf("there are no ACTIVE revisions of %s before %d", *current.TaskDefinition.Family, current.TaskDefinition.Revision)
+	}
+
+	target, err := pickRevision(previous, input.Revision, input.Steps)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("the service is running revision %d, these are the previous ACTIVE revisions:\n", current.TaskDefinition.Revision)
+	for _, arn := range previous {
+		if arn == target {
+			log.Println(color.GreenString("  * %s", arn))
+		} else {
+			log.Printf("    %s\n", arn)
+		}
+	}
+
+	output, err := s.client.DescribeTaskDefinition(ctx, target)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get target task definition")
+	}
+
+	diff := models.CompareTaskDefinitions(s.client.CopiedTaskDefinition(current), s.client.CopiedTaskDefinition(output))
+	if diff.Empty() {
+		log.Printf("%s has the same configuration as the current revision\n", target)
+	} else {
+		log.Println("these are the changes:")
+		log.Println(diff)
+	}
+
+	if input.DryRun {
+		log.Println("not proceeding with the rollback because this is a dry run :)")
+		return nil
+	}
+
+	if input.Confirm != nil {
+		proceed, err := input.Confirm(fmt.Sprintf("roll %s back to %s?", input.Service, target))
+		if err != nil {
+			return errorx.Decorate(err, "unable to confirm rollback")
+		}
+		if !proceed {
+			log.Println("not proceeding with the rollback")
+			return nil
+		}
+	}
+
+	if usesCodeDeploy(service) {
+		return s.rollbackWithCodeDeploy(ctx, input, service, target)
+	}
+
+	newService, err := s.client.UpdateTaskDefinition(ctx, service, output.TaskDefinition)
+	if err != nil {
+		return errorx.Decorate(err, "unable to update service")
+	}
+
+	if input.NoWait {
+		log.Println("not waiting for your service to reflect the changes, check the console instead.")
+		return nil
+	}
+
+	log.Println("waiting for the service to reflect the changes")
+	if err := s.client.WaitForServiceToLookGood(ctx, newService, input.Timeout, progress(log.Default())); err != nil {
+		return errorx.Decorate(err, "service did not reflect changes")
+	}
+
+	log.Println(color.GreenString("service has been rolled back successfully!"))
+	return nil
+}
+
+// rollbackWithCodeDeploy points services using the CODE_DEPLOY controller back
+// to the target through a blue/green deployment, ECS won't take it directly
+func (s *deployerService) rollbackWithCodeDeploy(ctx context.Context, input *RollbackInput, service *types.Service, target string) error {
+	content, err := models.NewAppSpec(service, target, nil).Content()
+	if err != nil {
+		return errorx.Decorate(err, "unable to build the AppSpec")
+	}
+	application, deploymentGroup := codeDeployNames(input.Cluster, input.CodeDeployApplication, input.CodeDeployDeploymentGroup, service)
+	log.Printf("creating a CodeDeploy deployment:\n  application: %s\n  deployment group: %s\n", application, deploymentGroup)
+	deploymentID, err := s.codeDeploy.CreateDeployment(ctx, application, deploymentGroup, content)
+	if err != nil {
+		return errorx.Decorate(err, "unable to create CodeDeploy deployment")
+	}
+
+	if input.NoWait {
+		log.Printf("not waiting for the deployment %s to finish, check the console instead.\n", deploymentID)
+		return nil
+	}
+
+	log.Printf("waiting for the deployment %s to finish\n", deploymentID)
+	if err := s.codeDeploy.WaitForDeployment(ctx, deploymentID, input.Timeout, progress(log.Default())); err != nil {
+		return errorx.Decorate(err, "CodeDeploy deployment did not succeed")
+	}
+
+	log.Println(color.GreenString("service has been rolled back successfully!"))
+	return nil
+}
+
+// pickRevision chooses the rollback target out of the previous revisions,
+// which are sorted newest first
+func pickRevision(previous []string, revision int32, steps int) (string, error) {
+	if revision != 0 {
+		for _, arn := range previous {
+			if revisionOf(arn) == revision {
+				return arn, nil
+			}
+		}
+		return "", fmt.Errorf("revision %d is not a previous ACTIVE revision", revision)
+	}
+	if steps < 1 {
+		steps = 1
+	}
+	if steps > len(previous) {
+		return "", fmt.Errorf("can't go back %d revisions, there are only %d previous ACTIVE revisions", steps, len(previous))
+	}
+	return previous[steps-1], nil
+}
+
+// revisionOf extracts the revision out of a task definition ARN
+func revisionOf(arn string) int32 {
+	revision, err := strconv.ParseInt(arn[strings.LastIndex(arn, ":")+1:], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(revision)
+}
@@ -0,0 +1,288 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adroll/ecs-ship/clients"
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var familyRevisions = []string{
+	"arn:aws:ecs:us-west-2:123:task-definition/family:5",
+	"arn:aws:ecs:us-west-2:123:task-definition/family:4",
+	"arn:aws:ecs:us-west-2:123:task-definition/family:2",
+	"arn:aws:ecs:us-west-2:123:task-definition/family:1",
+}
+
+func Test_Deployer_Rollback_PreviousRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster: "cluster",
+		Service: "service",
+		Steps:   1,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String(familyRevisions[1]),
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String(familyRevisions[1]),
+			Family:            aws.String("family"),
+			Revision:          4,
+			Cpu:               aws.String("512"),
+		},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("512")})
+	target := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String(familyRevisions[2]), Cpu: aws.String("256")},
+	}
+	newService := &types.Service{ServiceName: aws.String("service"), ClusterArn: aws.String("arn::cluster")}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, familyRevisions[2]).Return(target, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(target).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("256")})
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, target.TaskDefinition).Return(newService, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_Rollback_ToRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster:  "cluster",
+		Service:  "service",
+		Revision: 1,
+		NoWait:   true,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String(familyRevisions[1]),
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String(familyRevisions[1]),
+			Family:            aws.String("family"),
+			Revision:          4,
+			Cpu:               aws.String("512"),
+		},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("512")})
+	target := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String(familyRevisions[3])},
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, familyRevisions[3]).Return(target, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(target).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().UpdateTaskDefinition(ctx, service, target.TaskDefinition).Return(service, nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_Rollback_RevisionIsNotPrevious(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster:  "cluster",
+		Service:  "service",
+		Revision: 5,
+	}
+
+	service := &types.Service{TaskDefinition: aws.String(familyRevisions[1])}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{Family: aws.String("family"), Revision: 4},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.Error(t, err)
+	assert.Equal(t, "revision 5 is not a previous ACTIVE revision", err.Error())
+}
+
+func Test_Deployer_Rollback_TooManySteps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster: "cluster",
+		Service: "service",
+		Steps:   3,
+	}
+
+	service := &types.Service{TaskDefinition: aws.String(familyRevisions[1])}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{Family: aws.String("family"), Revision: 4},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.Error(t, err)
+	assert.Equal(t, "can't go back 3 revisions, there are only 2 previous ACTIVE revisions", err.Error())
+}
+
+func Test_Deployer_Rollback_NotConfirmed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	var question string
+	input := &services.RollbackInput{
+		Cluster: "cluster",
+		Service: "service",
+		Confirm: func(q string) (bool, error) {
+			question = q
+			return false, nil
+		},
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String(familyRevisions[1]),
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String(familyRevisions[1]),
+			Family:            aws.String("family"),
+			Revision:          4,
+			Cpu:               aws.String("512"),
+		},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{Cpu: aws.String("512")})
+	target := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String(familyRevisions[2])},
+	}
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, familyRevisions[2]).Return(target, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(target).Return(&ecs.RegisterTaskDefinitionInput{})
+
+	err := deployer.Rollback(ctx, input)
+	assert.Nil(t, err)
+	assert.Equal(t, "roll service back to "+familyRevisions[2]+"?", question)
+}
+
+func Test_Deployer_Rollback_CodeDeploy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockCodeDeploy := mock_clients.NewMockCodeDeployClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithCodeDeployClient(mockCodeDeploy))
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster: "arn:aws:ecs:us-east-1:123:cluster/cluster",
+		Service: "service",
+		Steps:   1,
+	}
+
+	service := &types.Service{
+		ServiceName:          aws.String("service"),
+		ClusterArn:           aws.String("arn::cluster"),
+		TaskDefinition:       aws.String(familyRevisions[1]),
+		DeploymentController: &types.DeploymentController{Type: types.DeploymentControllerTypeCodeDeploy},
+	}
+	current := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			TaskDefinitionArn: aws.String(familyRevisions[1]),
+			Family:            aws.String("family"),
+			Revision:          4,
+		},
+	}
+	target := &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{TaskDefinitionArn: aws.String(familyRevisions[2])},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(current, nil)
+	mockClient.EXPECT().ListTaskDefinitionRevisions(ctx, "family").Return(familyRevisions, nil)
+	mockClient.EXPECT().DescribeTaskDefinition(ctx, familyRevisions[2]).Return(target, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(current).Return(&ecs.RegisterTaskDefinitionInput{})
+	mockClient.EXPECT().CopiedTaskDefinition(target).Return(&ecs.RegisterTaskDefinitionInput{})
+	// ECS rejects task definition updates of CODE_DEPLOY services
+	mockClient.EXPECT().UpdateTaskDefinition(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockCodeDeploy.EXPECT().CreateDeployment(ctx, "AppECS-cluster-service", "DgpECS-cluster-service", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, appSpec string) (string, error) {
+			assert.Contains(t, appSpec, `"TaskDefinition":"`+familyRevisions[2]+`"`)
+			return "d-123", nil
+		})
+	mockCodeDeploy.EXPECT().WaitForDeployment(ctx, "d-123", input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_Rollback_CodeDeployWithoutClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.RollbackInput{Cluster: "cluster", Service: "service", Steps: 1}
+
+	service := &types.Service{
+		ServiceName:          aws.String("service"),
+		ClusterArn:           aws.String("arn::cluster"),
+		TaskDefinition:       aws.String(familyRevisions[1]),
+		DeploymentController: &types.DeploymentController{Type: types.DeploymentControllerTypeCodeDeploy},
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+
+	err := deployer.Rollback(ctx, input)
+	assert.ErrorContains(t, err, "there's no CodeDeploy client")
+}
+
+func Test_Deployer_Rollback_LockedByAnotherDeploy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockLocks := mock_clients.NewMockLockStore(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithLockStore(mockLocks))
+	ctx := context.Background()
+	input := &services.RollbackInput{
+		Cluster:   "cluster",
+		Service:   "service",
+		Steps:     1,
+		LockOwner: "bob",
+	}
+
+	service := &types.Service{
+		ServiceArn:     aws.String("arn::service"),
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String(familyRevisions[1]),
+	}
+	heldErr := &clients.LockHeldError{Key: "arn::service", Lock: clients.Lock{Owner: "alice"}}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockLocks.EXPECT().Acquire(ctx, "arn::service", "bob").Return(heldErr)
+
+	err := deployer.Rollback(ctx, input)
+	assert.ErrorIs(t, err, heldErr)
+	assert.Contains(t, err.Error(), "another deploy of service is in progress")
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/joomcode/errorx"
+)
+
+// updateScheduledTasks points the EventBridge rule targets and schedules
+// running the old task definition of the service to the new one
+func (s *deployerService) updateScheduledTasks(ctx context.Context, logger *log.Logger, clusterArn string, oldTaskDefinition string, newTaskDefinition string) error {
+	tasks, err := s.scheduledTasks.ListScheduledTasks(ctx, clusterArn, oldTaskDefinition)
+	if err != nil {
+		return errorx.Decorate(err, "unable to find the scheduled tasks")
+	}
+	if len(tasks) == 0 {
+		logger.Printf("there are no scheduled tasks running %s\n", oldTaskDefinition)
+		return nil
+	}
+
+	logger.Printf("pointing %d scheduled tasks to %s\n", len(tasks), newTaskDefinition)
+	for _, task := range tasks {
+		if err := s.scheduledTasks.UpdateScheduledTask(ctx, task, newTaskDefinition); err != nil {
+			return err
+		}
+		logger.Printf("  updated %s %s\n", task.Kind, task.Name)
+	}
+	return nil
+}
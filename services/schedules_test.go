@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adroll/ecs-ship/clients"
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/models"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_UpdateScheduledTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	mockScheduledTasks := mock_clients.NewMockScheduledTaskClient(ctrl)
+	deployer := services.NewDeployerService(mockClient, services.WithScheduledTaskClient(mockScheduledTasks))
+	ctx := context.Background()
+	input := &services.DeployInput{
+		Cluster: "cluster",
+		Service: "service",
+		NewConfig: models.TaskConfig{
+			CPU: aws.String("256"),
+		},
+		UpdateScheduledTasks: true,
+	}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::task:1"),
+	}
+	newTaskDefinition := &types.TaskDefinition{TaskDefinitionArn: aws.String("arn::task:2")}
+	newService := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	scheduledTasks := []*clients.ScheduledTask{
+		{Kind: clients.ScheduledTaskRule, Name: "default/nightly/job", TaskDefinition: "arn::task:1"},
+		{Kind: clients.ScheduledTaskSchedule, Name: "default/hourly", TaskDefinition: "arn::task:1"},
+	}
+	taskDefinitionOutput := &ecs.DescribeTaskDefinitionOutput{}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().DoesServiceLookGood(ctx, service).Return(true, nil)
+	mockClient.EXPECT().GetTaskDefinition(ctx, service).Return(taskDefinitionOutput, nil)
+	mockClient.EXPECT().CopiedTaskDefinition(taskDefinitionOutput).Return(&ecs.RegisterTaskDefinitionInput{})
+	gomock.InOrder(
+		mockClient.EXPECT().RegisterTaskDefinition(ctx, gomock.Any()).Return(newTaskDefinition, nil),
+		mockClient.EXPECT().UpdateTaskDefinition(ctx, service, newTaskDefinition).Return(newService, nil),
+		mockClient.EXPECT().WaitForServiceToLookGood(ctx, newService, input.Timeout, gomock.Any()).Return(nil),
+		mockScheduledTasks.EXPECT().ListScheduledTasks(ctx, "arn::cluster", "arn::task:1").Return(scheduledTasks, nil),
+		mockScheduledTasks.EXPECT().UpdateScheduledTask(ctx, scheduledTasks[0], "arn::task:2").Return(nil),
+		mockScheduledTasks.EXPECT().UpdateScheduledTask(ctx, scheduledTasks[1], "arn::task:2").Return(nil),
+	)
+
+	err := deployer.Deploy(ctx, input)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+)
+
+// WaitInput represents the input for waiting on a service someone else deployed
+type WaitInput struct {
+	// Cluster is the name of the ECS cluster
+	Cluster string
+	// Service is the name of the ECS service
+	Service string
+	// Timeout is the time to wait for the service to reach a steady state
+	Timeout time.Duration
+}
+
+func (s *deployerService) Wait(ctx context.Context, input *WaitInput) error {
+	log.Printf("waiting for service:\n  cluster: %s\n  service: %s\n", input.Cluster, input.Service)
+	service, err := s.client.GetService(ctx, input.Cluster, input.Service)
+	if err != nil {
+		return errorx.Decorate(err, "unable to get service")
+	}
+
+	log.Printf("waiting for the service to be running %s\n", aws.ToString(service.TaskDefinition))
+	if err := s.client.WaitForServiceToLookGood(ctx, service, input.Timeout, progress(log.Default())); err != nil {
+		return errorx.Decorate(err, "service did not reach a steady state")
+	}
+
+	log.Println(color.GreenString("service is running its task definition successfully!"))
+	return nil
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mock_clients "github.com/adroll/ecs-ship/clients/mocks"
+	"github.com/adroll/ecs-ship/services"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Deployer_Wait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.WaitInput{Cluster: "cluster", Service: "service", Timeout: time.Minute}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::web:6"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, service, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Wait(ctx, input)
+	assert.Nil(t, err)
+}
+
+func Test_Deployer_WaitTimesOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.WaitInput{Cluster: "cluster", Service: "service", Timeout: time.Minute}
+
+	service := &types.Service{
+		ServiceName:    aws.String("service"),
+		ClusterArn:     aws.String("arn::cluster"),
+		TaskDefinition: aws.String("arn::web:6"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, service, input.Timeout, gomock.Any()).Return(assert.AnError)
+
+	err := deployer.Wait(ctx, input)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "service did not reach a steady state")
+}
+
+func Test_Deployer_WaitWithoutTaskDefinition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_clients.NewMockECSClient(ctrl)
+	deployer := services.NewDeployerService(mockClient)
+	ctx := context.Background()
+	input := &services.WaitInput{Cluster: "cluster", Service: "service", Timeout: time.Minute}
+
+	service := &types.Service{
+		ServiceName: aws.String("service"),
+		ClusterArn:  aws.String("arn::cluster"),
+	}
+	mockClient.EXPECT().GetService(ctx, input.Cluster, input.Service).Return(service, nil)
+	mockClient.EXPECT().WaitForServiceToLookGood(ctx, service, input.Timeout, gomock.Any()).Return(nil)
+
+	err := deployer.Wait(ctx, input)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"github.com/adroll/ecs-ship/services"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+)
+
+func waitCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "wait",
+		Usage:     "Wait for a service deployed by something else to reach a steady state",
+		ArgsUsage: "<cluster> <service>",
+		UsageText: "ecs-deploy wait [options] <cluster> <service>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			color.NoColor = color.NoColor || cmd.Bool("no-color")
+
+			if cmd.NArg() != 2 {
+				return usageError(cmd, "Please specify a cluster and service to wait for")
+			}
+			args := cmd.Args()
+
+			svc, err := newDeployer(ctx, cmd, flagsTarget(cmd))
+			if err != nil {
+				return err
+			}
+
+			return exitError(svc.Wait(ctx, &services.WaitInput{
+				Cluster: args.Get(0),
+				Service: args.Get(1),
+				Timeout: cmd.Duration("timeout"),
+			}))
+		},
+	}
+}